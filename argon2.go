@@ -15,16 +15,26 @@
 package argon2
 
 import (
+	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"database/sql"
 	"database/sql/driver"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/text/unicode/norm"
 )
 
 const (
@@ -35,10 +45,29 @@ const (
 
 	saltLength = 16
 
+	maxMemory = 1024 * 1024
+
+	maxCostIterations  = 64
+	maxCostParallelism = 16
+
+	defaultMaxLength = 4096
+
+	minMemoryPerLane = 8
+	minKeyLength     = 4
+
+	minDigestLength = 16
+	maxDigestLength = 64
+	minSaltLength   = 8
+
+	derivedLength = 32
+
 	encodedSlicesCount = 6
 )
 
 var (
+	// ErrInvalid is returned when an operation requires a valid hash but the hash is invalid.
+	ErrInvalid = errors.New("the hash is not valid")
+
 	// ErrInvalidEncodedHash is returned when the encoded hash is in an invalid format.
 	ErrInvalidEncodedHash = errors.New("the encoded hash is not in the correct format")
 
@@ -49,31 +78,184 @@ var (
 	// ErrScan is returned when the given value to scanner cannot be represented as a ULID.
 	ErrScan = errors.New("cannot scan the given value")
 
+	// ErrWrongSeparator is returned when the encoded hash uses a separator other than "$" between its fields.
+	ErrWrongSeparator = errors.New("the encoded hash uses the wrong field separator")
+
+	// ErrNonCanonicalBase64 is returned when a segment of the encoded hash is not in its canonical encoding.
+	ErrNonCanonicalBase64 = errors.New("the encoded hash is not in canonical base64")
+
 	// ErrMismatched is returned when the given value to compare is not the same as the current hashed value.
 	ErrMismatched = errors.New("the given value is not the same as the current hashed value")
+
+	// ErrInvalidOption is returned when an option is given an unacceptable value.
+	ErrInvalidOption = errors.New("invalid option")
+
+	// ErrInvalidSalt is returned when a given or decoded salt does not fit the configured parameters.
+	ErrInvalidSalt = errors.New("invalid salt")
+
+	// ErrInvalidDigest is returned when the hashed value of an encoded hash cannot be decoded or has an
+	// unacceptable length.
+	ErrInvalidDigest = errors.New("invalid digest")
+
+	// ErrInvalidParams is returned when the parameters of an encoded hash cannot be decoded or are out of bounds.
+	ErrInvalidParams = errors.New("invalid parameters")
+
+	// ErrRandomSource is returned when the source of randomness fails to provide enough bytes.
+	ErrRandomSource = errors.New("the random source failed")
+
+	// ErrAssociatedData is returned when comparing against a hash computed with associated data, which
+	// golang.org/x/crypto/argon2 cannot compute.
+	ErrAssociatedData = errors.New("hashes with associated data cannot be verified")
+
+	// ErrCostExceeded is returned when an encoded hash requires more resources than the configured maximum cost.
+	ErrCostExceeded = errors.New("the encoded hash exceeds the maximum cost")
+
+	// ErrMemoryBudgetExceeded is returned when a hash requires more memory than its declared budget.
+	ErrMemoryBudgetExceeded = errors.New("the hash exceeds its declared memory budget")
+
+	// ErrUnknownKeyID is returned when the key id of a hash names no pepper of the configured pepper map.
+	ErrUnknownKeyID = errors.New("unknown key id")
 )
 
+// defaultMaxCost is the maximum cost of the hashes accepted when decoding or comparing, unless configured otherwise.
+var defaultMaxCost = Params{
+	Memory:      maxMemory,
+	Iterations:  maxCostIterations,
+	Parallelism: maxCostParallelism,
+}
+
+// DecodeError is returned when a segment of an encoded hash cannot be decoded.
+//
+// Besides its underlying error, it matches argon2.ErrInvalidEncodedHash, and argon2.ErrInvalidParams,
+// argon2.ErrInvalidSalt or argon2.ErrInvalidDigest for the params, salt and hash segments respectively.
+type DecodeError struct {
+	// Field names the segment: "prefix", "variant", "version", "params", "salt" or "hash".
+	Field string
+
+	// Value is the raw content of the segment.
+	Value string
+
+	// Err is the reason the segment cannot be decoded.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode %s %q: %s", e.Field, e.Value, e.Err)
+}
+
+// Unwrap returns the reason the segment cannot be decoded.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is argon2.ErrInvalidEncodedHash or the sentinel of the segment.
+func (e *DecodeError) Is(target error) bool {
+	switch e.Field {
+	case "params":
+		return target == ErrInvalidEncodedHash || target == ErrInvalidParams
+	case "salt":
+		return target == ErrInvalidEncodedHash || target == ErrInvalidSalt
+	case "hash":
+		return target == ErrInvalidEncodedHash || target == ErrInvalidDigest
+	default:
+		return target == ErrInvalidEncodedHash
+	}
+}
+
+// randomSourceError is returned when the source of randomness fails.
+//
+// Besides its underlying error, it matches argon2.ErrRandomSource.
+type randomSourceError struct {
+	err error
+}
+
+func (e randomSourceError) Error() string {
+	return fmt.Sprintf("failed to generate random bytes: %s", e.err)
+}
+
+func (e randomSourceError) Unwrap() error {
+	return e.err
+}
+
+func (e randomSourceError) Is(target error) bool {
+	return target == ErrRandomSource
+}
+
 // Argon2 provides Argon2 based hashing operations.
+//
+// An argon2.Argon2 is immutable once created or decoded, apart from Wipe and the Scan and Unmarshal
+// methods, so its other methods, such as Compare, String and Value, are safe for concurrent use. Calling
+// a mutating method concurrently with any other method on the same value, or on a copy of it, is a data race.
 type Argon2 struct {
+	variant     Variant
+	version     int
 	salt        []byte
 	iterations  uint32
 	memory      uint32
 	parallelism uint8
 	keyLength   uint32
+	budget      uint32
 	hashed      []byte
+	encoder     Encoder
+	pepper      []byte
+	keyID       []byte
+	data        []byte
 	isValid     bool
+
+	externalSalt bool
+	normalize    bool
+	form         norm.Form
+
+	// uniformTiming is the minimum duration of a comparison, or zero for no padding.
+	uniformTiming time.Duration
+
+	// fallbackPeppers are tried in turn after the pepper on comparison, e.g. the previous one of a rotation.
+	fallbackPeppers [][]byte
+
+	// unknownKeyID is set when the key id of the hash names no pepper of the configured pepper map.
+	unknownKeyID bool
+
+	// maxCost is the maximum cost accepted on comparison, or the zero value for defaultMaxCost.
+	maxCost Params
+}
+
+// CompatibleVersions lists the argon2 versions accepted when decoding.
+//
+// Hashes of any listed version are decoded and keep their version when encoded again, but only hashes of
+// the current argon2.Version can be computed: comparing against any other fails with
+// argon2.ErrIncompatibleVersion, rather than reporting a mismatch for the right password. Adding older
+// versions lets legacy hashes be inspected, e.g. to report them as needing a reset; it is meant to be set
+// once at startup.
+var CompatibleVersions = []int{argon2.Version}
+
+func isCompatibleVersion(version int, allowed []int) bool {
+	for _, versions := range [][]int{CompatibleVersions, allowed} {
+		for _, v := range versions {
+			if v == version {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
+// OnCompareFail, when set, is called with the redacted encoded hash each time Compare finds a mismatch.
+//
+// It is not called when the comparison fails for any other reason, such as an exceeded memory budget.
+// It is meant to be set once at startup, for example to implement lockout or cost escalation.
+var OnCompareFail func(encoded string)
+
 var _ sql.Scanner = (*Argon2)(nil)
 var _ driver.Valuer = Argon2{}
 var _ fmt.Stringer = Argon2{}
 
-func (a *Argon2) makeSalt() error {
+func (a *Argon2) makeSalt(n uint32, r io.Reader) error {
 	if a.salt != nil {
 		return nil
 	}
 
-	salt, err := Bytes(saltLength)
+	salt, err := readBytes(r, n)
 	if err != nil {
 		return err
 	}
@@ -83,9 +265,35 @@ func (a *Argon2) makeSalt() error {
 	return nil
 }
 
-func (a *Argon2) makeHash(toHash string) {
-	a.hashed = argon2.IDKey(
-		[]byte(toHash),
+func (a *Argon2) makeHash(toHash []byte) {
+	a.hashed = a.derive(toHash)
+}
+
+// derive returns the key derived from the given bytes with the salt and parameters of the hash.
+func (a Argon2) derive(toHash []byte) []byte {
+	if a.normalize {
+		toHash = a.form.Bytes(toHash)
+	}
+
+	if a.pepper != nil {
+		mac := hmac.New(sha256.New, a.pepper)
+		mac.Write(toHash)
+		toHash = mac.Sum(nil)
+	}
+
+	if a.variant == VariantI {
+		return argon2.Key(
+			toHash,
+			a.salt,
+			a.iterations,
+			a.memory,
+			a.parallelism,
+			a.keyLength,
+		)
+	}
+
+	return kdf.IDKey(
+		toHash,
 		a.salt,
 		a.iterations,
 		a.memory,
@@ -94,6 +302,175 @@ func (a *Argon2) makeHash(toHash string) {
 	)
 }
 
+func (a *Argon2) decodeParams(encoded string) error {
+	seen := make([]string, 0, 6)
+
+	for field, rest, more := "", encoded, true; more; {
+		field, rest, more = strings.Cut(rest, ",")
+
+		key, val, ok := strings.Cut(field, "=")
+		if !ok {
+			return fmt.Errorf("%w: malformed parameter %q", ErrInvalidEncodedHash, field)
+		}
+
+		key = strings.TrimSpace(key)
+		val = strings.TrimSpace(val)
+
+		if key == "keyid" || key == "data" {
+			b, bytesErr := decodeBase64(val)
+			if bytesErr != nil {
+				return fmt.Errorf("%w: malformed parameter %q", ErrInvalidEncodedHash, field)
+			}
+
+			if key == "keyid" {
+				a.keyID = b
+			} else {
+				a.data = b
+			}
+
+			seen = append(seen, key)
+
+			continue
+		}
+
+		bitSize := 32
+		if key == "p" {
+			bitSize = 8
+		}
+
+		n, err := strconv.ParseUint(val, 10, bitSize)
+		if err != nil {
+			return fmt.Errorf("%w: malformed parameter %q", ErrInvalidEncodedHash, field)
+		}
+
+		switch key {
+		case "m":
+			a.memory = uint32(n)
+		case "t":
+			a.iterations = uint32(n)
+		case "p":
+			a.parallelism = uint8(n)
+		case "budget":
+			a.budget = uint32(n)
+		default:
+			return fmt.Errorf("%w: unknown parameter %q", ErrInvalidEncodedHash, key)
+		}
+
+		seen = append(seen, key)
+	}
+
+	for _, key := range []string{"m", "t", "p"} {
+		if !contains(seen, key) {
+			return fmt.Errorf("%w: missing parameter %q", ErrInvalidEncodedHash, key)
+		}
+	}
+
+	return nil
+}
+
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Memory returns the memory parameter in KiB, or zero if the hash is invalid.
+func (a Argon2) Memory() uint32 {
+	if !a.isValid {
+		return 0
+	}
+
+	return a.memory
+}
+
+// Iterations returns the number of iterations, or zero if the hash is invalid.
+func (a Argon2) Iterations() uint32 {
+	if !a.isValid {
+		return 0
+	}
+
+	return a.iterations
+}
+
+// Parallelism returns the parallelism parameter, or zero if the hash is invalid.
+func (a Argon2) Parallelism() uint8 {
+	if !a.isValid {
+		return 0
+	}
+
+	return a.parallelism
+}
+
+// Valid reports whether the hash was successfully created or decoded.
+func (a Argon2) Valid() bool {
+	return a.isValid
+}
+
+// Variant returns the argon2 variant of the hash, or argon2.VariantID if the hash is invalid.
+func (a Argon2) Variant() Variant {
+	if !a.isValid {
+		return VariantID
+	}
+
+	return a.variant
+}
+
+// Version returns the argon2 version the hash declares, or zero if the hash is invalid.
+func (a Argon2) Version() int {
+	if !a.isValid {
+		return 0
+	}
+
+	return a.version
+}
+
+// KeyLength returns the length of the hashed value in bytes, or zero if the hash is invalid.
+func (a Argon2) KeyLength() uint32 {
+	if !a.isValid {
+		return 0
+	}
+
+	return a.keyLength
+}
+
+// SaltLength returns the length of the salt in bytes, or zero if the hash is invalid.
+//
+// For a decoded hash, it is the length of the decoded salt, or of the external salt if one was given.
+func (a Argon2) SaltLength() int {
+	if !a.isValid {
+		return 0
+	}
+
+	return len(a.salt)
+}
+
+// Salt returns a copy of the salt, or nil if the hash is invalid.
+func (a Argon2) Salt() []byte {
+	if !a.isValid {
+		return nil
+	}
+
+	return append([]byte(nil), a.salt...)
+}
+
+// Hash returns a copy of the hashed value, or nil if the hash is invalid.
+func (a Argon2) Hash() []byte {
+	if !a.isValid {
+		return nil
+	}
+
+	return append([]byte(nil), a.hashed...)
+}
+
+// MaxVerifyMemory returns the memory budget in KiB declared by the hash, or zero if it declares none.
+func (a Argon2) MaxVerifyMemory() uint32 {
+	return a.budget
+}
+
 // Scan implements sql.Scanner.
 func (a *Argon2) Scan(src interface{}) error {
 	if src == nil {
@@ -102,13 +479,25 @@ func (a *Argon2) Scan(src interface{}) error {
 		return nil
 	}
 
-	s, ok := src.(string)
-	if !ok {
-		return fmt.Errorf("%w: expected a string", ErrScan)
+	var err error
+
+	switch x := src.(type) {
+	case string:
+		if isJSONObject([]byte(x)) {
+			*a, err = newByJSONB([]byte(x))
+		} else {
+			*a, err = NewByEncoded(x)
+		}
+	case []byte:
+		if isJSONObject(x) {
+			*a, err = newByJSONB(x)
+		} else {
+			*a, err = NewByEncoded(string(x))
+		}
+	default:
+		return fmt.Errorf("%w: expected a string or a byte slice", ErrScan)
 	}
 
-	var err error
-	*a, err = NewByEncoded(s)
 	if err != nil {
 		return fmt.Errorf("cannot scan due to decode error: %w", err)
 	}
@@ -122,129 +511,1015 @@ func (a Argon2) Value() (driver.Value, error) {
 		return nil, nil
 	}
 
-	return a.String(), nil
+	return a.Encode(), nil
 }
 
 // Encode returns an encoded value of the hash.
-func (a Argon2) String() string {
+func (a Argon2) Encode() string {
 	if !a.isValid {
 		return ""
 	}
 
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", a.memory, a.iterations, a.parallelism)
+	if a.budget != 0 {
+		params += fmt.Sprintf(",budget=%d", a.budget)
+	}
+
+	if a.keyID != nil {
+		params += ",keyid=" + base64.RawStdEncoding.EncodeToString(a.keyID)
+	}
+
+	if a.data != nil {
+		params += ",data=" + base64.RawStdEncoding.EncodeToString(a.data)
+	}
+
+	salt := a.encoding().Encode(a.salt)
+	if a.externalSalt {
+		salt = ""
+	}
+
+	return fmt.Sprintf(
+		"$%s$v=%d$%s$%s$%s",
+		a.variant,
+		a.version,
+		params,
+		salt,
+		a.encoding().Encode(a.hashed),
+	)
+}
+
+// GoString implements fmt.GoStringer.
+//
+// It describes the variant and parameters of the hash, leaving out its salt and hashed value.
+func (a Argon2) GoString() string {
+	if !a.isValid {
+		return "argon2.Argon2{valid:false}"
+	}
+
 	return fmt.Sprintf(
-		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
-		argon2.Version,
+		"argon2.Argon2{variant:%s, m:%d, t:%d, p:%d, keyLen:%d, valid:true}",
+		a.variant,
 		a.memory,
 		a.iterations,
 		a.parallelism,
-		base64.RawStdEncoding.EncodeToString(a.salt),
-		base64.RawStdEncoding.EncodeToString(a.hashed),
+		a.keyLength,
 	)
 }
 
+// String implements fmt.Stringer.
+//
+// It returns the encoded hash with its salt and hashed value redacted, so that logging the hash does not
+// leak them. Use Encode to store the hash.
+func (a Argon2) String() string {
+	return a.redacted()
+}
+
+// redacted returns the encoded hash with its salt and hashed value omitted.
+func (a Argon2) redacted() string {
+	if !a.isValid {
+		return ""
+	}
+
+	vals := strings.Split(a.Encode(), "$")
+
+	return strings.Join(append(vals[:4], "[redacted]"), "$")
+}
+
+// Hash64 returns a 64-bit FNV-1a hash of the encoded value, for bucketing and set membership.
+//
+// It is not cryptographic: distinct hashes may collide, so a match must be confirmed with the encoded values.
+func (a Argon2) Hash64() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(a.Encode()))
+
+	return h.Sum64()
+}
+
 // Compare compares the current hashed value with the given one.
+//
+// An invalid hash, e.g. the zero value, never matches and results in argon2.ErrInvalid. If the hash
+// declares a memory budget, it is enforced before any computation takes place. Otherwise, the
+// key is always derived in full and compared in constant time, even when its length does not match.
 func (a Argon2) Compare(toCompare string) error {
-	b := &Argon2{
-		salt:        a.salt,
-		iterations:  a.iterations,
-		memory:      a.memory,
-		parallelism: a.parallelism,
-		keyLength:   a.keyLength,
-		isValid:     true,
+	ok, err := a.CompareErr(toCompare)
+	if err == nil && !ok {
+		return ErrMismatched
+	}
+
+	return err
+}
+
+// CompareErr reports whether the given value matches the current hashed value.
+//
+// Unlike Compare, a mismatch is reported as false with no error, so an error always means the hash itself
+// cannot be compared against: argon2.ErrInvalid for an invalid hash, e.g. the zero value, argon2.ErrInvalidDigest
+// for an empty hashed value and argon2.ErrInvalidParams for parameters argon2 cannot compute.
+func (a Argon2) CompareErr(toCompare string) (bool, error) {
+	err := a.CompareBytes([]byte(toCompare))
+	if errors.Is(err, ErrMismatched) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareBytes compares the current hashed value with the given bytes.
+//
+// It behaves like Compare, without converting the value to a string.
+func (a Argon2) CompareBytes(toCompare []byte) error {
+	if !a.isValid {
+		return ErrInvalid
+	}
+
+	if len(a.hashed) == 0 {
+		return fmt.Errorf("%w: the hashed value is empty", ErrInvalidDigest)
+	}
+
+	if err := a.checkBounds(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidParams, err)
 	}
 
-	b.makeHash(toCompare)
+	return a.compareDigest(toCompare, a.hashed)
+}
+
+// compareDigest compares the key derived from the given bytes with the given digest.
+func (a Argon2) compareDigest(toCompare, digest []byte) error {
+	if a.uniformTiming > 0 {
+		defer padTiming(time.Now(), a.uniformTiming)
+	}
+
+	if err := a.checkComparable(); err != nil {
+		return err
+	}
 
-	if subtle.ConstantTimeCompare(a.hashed, b.hashed) == 1 {
+	if a.matches(toCompare, digest) {
 		return nil
 	}
 
+	if OnCompareFail != nil {
+		OnCompareFail(a.redacted())
+	}
+
 	return ErrMismatched
 }
 
-// New returns a new argon2.Argon2 by hashing the given string.
-func New(toHash string) (Argon2, error) {
-	a := Argon2{
-		memory:      memory,
-		iterations:  iterations,
-		parallelism: parallelism,
-		keyLength:   keyLength,
-		isValid:     true,
-	}
+// matches reports whether the key derived from the given bytes with any of the peppers of the hash equals
+// the given digest.
+func (a Argon2) matches(toCompare, digest []byte) bool {
+	for _, pepper := range a.peppers() {
+		b := a
+		b.pepper = pepper
 
-	err := a.makeSalt()
-	if err != nil {
-		return Argon2{}, err
+		if constantTimeEqual(digest, b.derive(toCompare)) {
+			return true
+		}
 	}
 
-	a.makeHash(toHash)
+	return false
+}
 
-	return a, nil
+// peppers returns the pepper of the hash followed by its fallback peppers, in the order they are tried.
+func (a Argon2) peppers() [][]byte {
+	return append([][]byte{a.pepper}, a.fallbackPeppers...)
 }
 
-// MustNew forces argon2.New.
-func MustNew(toHash string) Argon2 {
-	a, err := New(toHash)
-	if err != nil {
-		panic(fmt.Errorf("failed to create: %w", err))
+// padTiming sleeps until the given duration has elapsed since the given start.
+func padTiming(start time.Time, d time.Duration) {
+	if remaining := d - time.Since(start); remaining > 0 {
+		time.Sleep(remaining)
+	}
+}
+
+// checkComparable verifies that a key can be derived to compare against the hash.
+func (a Argon2) checkComparable() error {
+	if a.data != nil {
+		return ErrAssociatedData
 	}
 
-	return a
+	if a.unknownKeyID {
+		return fmt.Errorf("%w: %q", ErrUnknownKeyID, a.keyID)
+	}
+
+	if a.version != argon2.Version {
+		return fmt.Errorf(
+			"%w: hashes of version %d cannot be computed, only of version %d",
+			ErrIncompatibleVersion,
+			a.version,
+			argon2.Version,
+		)
+	}
+
+	if a.budget != 0 && a.memory > a.budget {
+		return fmt.Errorf("%w: memory is %d KiB, budget is %d KiB", ErrMemoryBudgetExceeded, a.memory, a.budget)
+	}
+
+	return a.checkCost(a.costCeiling())
 }
 
-// NewByEncoded returns a new argon2.Argon2 by decoding the given previously encoded hash.
-func NewByEncoded(encoded string) (Argon2, error) {
-	vals := strings.Split(encoded, "$")
-	if len(vals) != encodedSlicesCount {
-		return Argon2{}, ErrInvalidEncodedHash
+// CompareDerived reports whether the current hashed value and the given one are equal.
+//
+// Digests are only comparable when both hashes share the same salt and parameters, e.g. a candidate
+// derived with argon2.NewWithEntropy using the stored salt. Otherwise, it returns false.
+func (a Argon2) CompareDerived(other Argon2) bool {
+	if !a.isValid || !other.isValid {
+		return false
 	}
 
-	var version int
-	_, err := fmt.Sscanf(vals[2], "v=%d", &version)
-	if err != nil {
-		return Argon2{}, fmt.Errorf("failed to decode: %w", err)
+	if a.memory != other.memory ||
+		a.iterations != other.iterations ||
+		a.parallelism != other.parallelism ||
+		a.keyLength != other.keyLength ||
+		subtle.ConstantTimeCompare(a.salt, other.salt) != 1 {
+		return false
 	}
-	if version != argon2.Version {
-		return Argon2{}, ErrIncompatibleVersion
+
+	return constantTimeEqual(a.hashed, other.hashed)
+}
+
+// CompareRaw reports whether the given raw key equals the current hashed value, without deriving a key.
+//
+// It is meant for setups where the key is derived elsewhere, e.g. by another service. The caller is
+// responsible for deriving it from the same salt, parameters and pepper as the current hash; a key derived
+// otherwise never matches. It returns false if the hash is invalid.
+func (a Argon2) CompareRaw(key []byte) bool {
+	if !a.isValid || len(a.hashed) == 0 {
+		return false
 	}
 
-	salt, err := base64.RawStdEncoding.DecodeString(vals[4])
-	if err != nil {
-		return Argon2{}, fmt.Errorf("failed to decode salt value: %w", err)
+	return constantTimeEqual(a.hashed, key)
+}
+
+// Equal reports whether the current value and the given one carry the same variant, version, parameters,
+// including the memory budget, salt, hashed value, key id and associated data.
+//
+// Unlike Compare, it does not check a plaintext against the hash. It returns false if either value is invalid.
+func (a Argon2) Equal(b Argon2) bool {
+	if !a.isValid || !b.isValid {
+		return false
 	}
 
-	hashed, err := base64.RawStdEncoding.DecodeString(vals[5])
-	if err != nil {
-		return Argon2{}, fmt.Errorf("failed to decode hashed value: %w", err)
+	if a.variant != b.variant ||
+		a.version != b.version ||
+		a.memory != b.memory ||
+		a.iterations != b.iterations ||
+		a.parallelism != b.parallelism ||
+		a.keyLength != b.keyLength ||
+		a.budget != b.budget {
+		return false
 	}
 
-	var m uint32
-	var i uint32
-	var p uint8
-	_, err = fmt.Sscanf(vals[3], "m=%d,t=%d,p=%d", &m, &i, &p)
-	if err != nil {
-		return Argon2{}, fmt.Errorf("failed to decode hash options: %w", err)
+	return subtle.ConstantTimeCompare(a.salt, b.salt)&
+		subtle.ConstantTimeCompare(a.hashed, b.hashed)&
+		subtle.ConstantTimeCompare(a.keyID, b.keyID)&
+		subtle.ConstantTimeCompare(a.data, b.data) == 1
+}
+
+// constantTimeEqual reports whether x and y are equal.
+//
+// Unlike subtle.ConstantTimeCompare, it does not return early when the lengths differ: y is padded or
+// truncated to the length of x and compared in full.
+func constantTimeEqual(x, y []byte) bool {
+	padded := y
+	if len(y) != len(x) {
+		padded = make([]byte, len(x))
+		copy(padded, y)
 	}
 
-	return Argon2{
-		salt:        salt,
-		iterations:  i,
-		memory:      m,
-		parallelism: p,
-		keyLength:   uint32(len(hashed)),
-		hashed:      hashed,
-		isValid:     true,
-	}, nil
+	sameLength := subtle.ConstantTimeEq(int32(len(x)), int32(len(y)))
+
+	return subtle.ConstantTimeCompare(x, padded)&sameLength == 1
 }
 
-// Bytes generates random bytes of the given size.
-func Bytes(n uint32) ([]byte, error) {
-	b := make([]byte, n)
+// CompareConstant reports whether the given value matches the current hashed value.
+//
+// Unlike Compare, an invalid hash does not return early: a dummy hash with the parameters of
+// argon2.Defaults is computed instead, so the time taken does not reveal whether a hash existed.
+func (a Argon2) CompareConstant(candidate string) bool {
+	if !a.isValid {
+		dummy := Argon2{
+			variant:     defaults.Variant,
+			salt:        make([]byte, defaults.SaltLength),
+			iterations:  defaults.Iterations,
+			memory:      defaults.Memory,
+			parallelism: defaults.Parallelism,
+			keyLength:   defaults.KeyLength,
+		}
 
-	_, err := rand.Read(b)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate random bytes: %w", err)
+		dummy.makeHash([]byte(candidate))
+
+		return false
 	}
 
-	return b, nil
+	return a.Compare(candidate) == nil
+}
+
+// CompareAny reports whether the given password matches any of the given hashes.
+//
+// Every hash is compared, whichever matches, so the time taken does not reveal which one did. The password
+// is hashed once per distinct set of salt, parameters, pepper and normalization, trying the fallback
+// peppers of a hash as well, e.g. after argon2.SecretFromEnv. Invalid hashes and hashes
+// that cannot be compared, e.g. ones exceeding their memory budget, never match.
+func CompareAny(password string, hashes []Argon2) bool {
+	derived := make(map[string][]byte, len(hashes))
+	match := 0
+
+	for _, h := range hashes {
+		if !h.isValid || h.checkComparable() != nil {
+			continue
+		}
+
+		for _, pepper := range h.peppers() {
+			key := fmt.Sprintf(
+				"%s$%d$%d$%d$%d$%x$%x$%t$%d",
+				h.variant,
+				h.memory,
+				h.iterations,
+				h.parallelism,
+				h.keyLength,
+				h.salt,
+				pepper,
+				h.normalize,
+				h.form,
+			)
+
+			candidate, ok := derived[key]
+			if !ok {
+				b := Argon2{
+					variant:     h.variant,
+					pepper:      pepper,
+					salt:        h.salt,
+					iterations:  h.iterations,
+					memory:      h.memory,
+					parallelism: h.parallelism,
+					keyLength:   h.keyLength,
+					normalize:   h.normalize,
+					form:        h.form,
+				}
+				b.makeHash([]byte(password))
+
+				candidate = b.hashed
+				derived[key] = candidate
+			}
+
+			if constantTimeEqual(h.hashed, candidate) {
+				match = 1
+			}
+		}
+	}
+
+	return match == 1
+}
+
+// CompareAndDerive compares the current hashed value with the given one and, on a match, returns a value
+// derived from the hashed value.
+//
+// The derived value is the first 32 bytes of HKDF-Expand with SHA-256, using the hashed value as the
+// pseudorandom key and the given info. It changes whenever the hash does, which makes it suitable for
+// binding sessions to the current password. On a mismatch, it returns false and nil.
+func (a Argon2) CompareAndDerive(candidate string, info []byte) (bool, []byte) {
+	if a.Compare(candidate) != nil {
+		return false, nil
+	}
+
+	derived := make([]byte, derivedLength)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, a.hashed, info), derived); err != nil {
+		return false, nil
+	}
+
+	return true, derived
+}
+
+// New returns a new argon2.Argon2 by hashing the given string.
+func New(toHash string, opts ...Option) (Argon2, error) {
+	return NewBytes([]byte(toHash), opts...)
+}
+
+// NewBytes returns a new argon2.Argon2 by hashing the given bytes.
+//
+// Unlike New, the value is not copied into a string, so the caller may wipe it afterwards,
+// or have it wiped with argon2.WithWipeInput.
+func NewBytes(toHash []byte, opts ...Option) (Argon2, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	return newBytes(toHash, o)
+}
+
+func newBytes(toHash []byte, o options) (Argon2, error) {
+	if o.wipeInput {
+		defer wipe(toHash)
+	}
+
+	err := o.check(toHash)
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	a := o.argon2()
+	a.salt = cloneBytes(o.salt)
+
+	err = a.makeSalt(o.saltLength, o.rand)
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	a.makeHash(toHash)
+
+	return a, nil
+}
+
+// NewContext returns a new argon2.Argon2 by hashing the given string, unless the given context is done first.
+//
+// The computation itself cannot be interrupted: if the context is done first, it returns the context's
+// error right away while the hashing finishes in the background and is discarded.
+func NewContext(ctx context.Context, toHash string, opts ...Option) (Argon2, error) {
+	if err := ctx.Err(); err != nil {
+		return Argon2{}, fmt.Errorf("failed to create: %w", err)
+	}
+
+	done := make(chan Result, 1)
+
+	go func() {
+		a, err := New(toHash, opts...)
+		done <- Result{Argon2: a, Err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return Argon2{}, fmt.Errorf("failed to create: %w", ctx.Err())
+	case r := <-done:
+		return r.Argon2, r.Err
+	}
+}
+
+// MustNew forces argon2.New.
+func MustNew(toHash string, opts ...Option) Argon2 {
+	a, err := New(toHash, opts...)
+	if err != nil {
+		panic(fmt.Errorf("failed to create: %w", err))
+	}
+
+	return a
+}
+
+// NewWithEntropy returns a new argon2.Argon2 by hashing the given string using the given entropy as its salt.
+//
+// The entropy must be exactly as long as the configured salt length. The security of the resulting hash
+// depends entirely on the quality of the given entropy: a predictable or reused salt allows precomputed
+// attacks against the hash. Unless reproducible output is a hard requirement, prefer argon2.New.
+func NewWithEntropy(toHash string, entropy []byte, opts ...Option) (Argon2, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	err = o.check([]byte(toHash))
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	if uint32(len(entropy)) != o.saltLength {
+		return Argon2{}, fmt.Errorf("%w: expected %d bytes of entropy, got %d", ErrInvalidSalt, o.saltLength, len(entropy))
+	}
+
+	a := o.argon2()
+	a.salt = append([]byte(nil), entropy...)
+	a.makeHash([]byte(toHash))
+
+	return a, nil
+}
+
+// NewByEncoded returns a new argon2.Argon2 by decoding the given previously encoded hash.
+//
+// Trailing line endings, either "\n" or "\r\n", are ignored.
+//
+// The optional key id and associated data, given as keyid and data parameters in the PHC string format, are
+// kept and encoded again. The key id only names the secret the hash was computed with and does not take
+// part in the comparison, but comparing against a hash with associated data fails with argon2.ErrAssociatedData.
+func NewByEncoded(encoded string, opts ...Option) (Argon2, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	return newByEncoded(encoded, o)
+}
+
+// Validate reports whether the given string is a well-formed encoded argon2id or argon2i hash.
+//
+// It performs the same checks as argon2.NewByEncoded with the given options, from the format of every
+// segment to the bounds of the parameters, except that any version is accepted: it does not tell whether
+// the hash can be verified by this package, only whether it is a syntactically valid hash.
+func Validate(encoded string, opts ...Option) error {
+	o, err := newOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	o.anyVersion = true
+
+	_, err = newByEncoded(encoded, o)
+
+	return err
+}
+
+// MustNewByEncoded forces argon2.NewByEncoded.
+func MustNewByEncoded(encoded string, opts ...Option) Argon2 {
+	a, err := NewByEncoded(encoded, opts...)
+	if err != nil {
+		panic(fmt.Errorf("failed to decode: %w", err))
+	}
+
+	return a
+}
+
+func newByEncoded(encoded string, o options) (Argon2, error) {
+	vals, err := splitEncoded(encoded)
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	return decodeSegments(vals, o)
+}
+
+// decodeSegments decodes the segments of an encoded hash and checks them against the given options.
+func decodeSegments(vals [encodedSlicesCount]string, o options) (Argon2, error) {
+	a, err := decodeHeader(vals, o)
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	hashed, err := o.encoder.Decode(vals[5])
+	if err != nil {
+		return Argon2{}, &DecodeError{Field: "hash", Value: vals[5], Err: err}
+	}
+
+	err = checkDigestLength(vals[5], len(hashed), o)
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	if o.canonicalOnly && o.encoder.Encode(hashed) != vals[5] {
+		return Argon2{}, ErrNonCanonicalBase64
+	}
+
+	a.hashed = hashed
+	a.keyLength = uint32(len(hashed))
+
+	return a, nil
+}
+
+// splitEncoded splits the given encoded hash into its segments.
+func splitEncoded(encoded string) ([encodedSlicesCount]string, error) {
+	var vals [encodedSlicesCount]string
+
+	encoded = strings.TrimRight(encoded, "\r\n")
+
+	rest, more := encoded, true
+	for idx := range vals {
+		if !more {
+			return vals, invalidFormat(encoded)
+		}
+
+		vals[idx], rest, more = strings.Cut(rest, "$")
+	}
+
+	if more {
+		return vals, invalidFormat(encoded)
+	}
+
+	if vals[0] != "" {
+		return vals, &DecodeError{
+			Field: "prefix",
+			Value: vals[0],
+			Err:   fmt.Errorf("%w: expected the hash to start with \"$\"", ErrInvalidEncodedHash),
+		}
+	}
+
+	return vals, nil
+}
+
+// invalidFormat returns the error for an encoded hash without the expected number of segments.
+func invalidFormat(encoded string) error {
+	if sep, ok := wrongSeparator(encoded); ok {
+		return fmt.Errorf(
+			"%w: found %q, expected the format $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>",
+			ErrWrongSeparator,
+			sep,
+		)
+	}
+
+	return ErrInvalidEncodedHash
+}
+
+// decodeHeader decodes every segment of an encoded hash but the hashed value.
+func decodeHeader(vals [encodedSlicesCount]string, o options) (Argon2, error) {
+	variant, err := parseVariant(vals[1])
+	if err != nil {
+		return Argon2{}, &DecodeError{Field: "variant", Value: vals[1], Err: err}
+	}
+
+	version, err := parseVersion(vals[2])
+	if err == nil && !o.anyVersion && !isCompatibleVersion(version, o.allowedVersions) {
+		err = ErrIncompatibleVersion
+	}
+
+	if err != nil {
+		return Argon2{}, &DecodeError{Field: "version", Value: vals[2], Err: err}
+	}
+
+	salt, err := o.encoder.Decode(vals[4])
+	if err != nil {
+		return Argon2{}, &DecodeError{Field: "salt", Value: vals[4], Err: err}
+	}
+
+	if o.canonicalOnly && o.encoder.Encode(salt) != vals[4] {
+		return Argon2{}, ErrNonCanonicalBase64
+	}
+
+	a := Argon2{
+		variant: variant,
+		version: version,
+		salt:    salt,
+		encoder: o.encoder,
+		pepper:  o.pepper,
+		isValid: true,
+
+		fallbackPeppers: o.fallbackPeppers,
+
+		normalize:     o.normalize,
+		form:          o.form,
+		uniformTiming: o.uniformTiming,
+		maxCost:       o.maxCost,
+	}
+
+	if len(salt) == 0 {
+		if o.externalSalt == nil {
+			return Argon2{}, &DecodeError{
+				Field: "salt",
+				Value: vals[4],
+				Err:   fmt.Errorf("%w: the salt is empty", ErrInvalidSalt),
+			}
+		}
+
+		a.salt = cloneBytes(o.externalSalt)
+		a.externalSalt = true
+	}
+
+	if len(a.salt) < int(o.minSaltLength) {
+		return Argon2{}, &DecodeError{
+			Field: "salt",
+			Value: vals[4],
+			Err: fmt.Errorf(
+				"%w: the salt is %d bytes long, expected at least %d",
+				ErrInvalidSalt,
+				len(a.salt),
+				o.minSaltLength,
+			),
+		}
+	}
+
+	err = a.decodeParams(vals[3])
+	if err != nil {
+		return Argon2{}, &DecodeError{Field: "params", Value: vals[3], Err: err}
+	}
+
+	o.selectPepper(&a)
+
+	memory, memoryOK := scaleMemory(a.memory, o.memoryUnit)
+	budget, budgetOK := scaleMemory(a.budget, o.memoryUnit)
+
+	if !memoryOK || !budgetOK {
+		return Argon2{}, &DecodeError{
+			Field: "params",
+			Value: vals[3],
+			Err:   fmt.Errorf("%w: memory overflows in the given unit", ErrInvalidEncodedHash),
+		}
+	}
+
+	a.memory = memory
+	a.budget = budget
+
+	err = a.checkBounds()
+	if err != nil {
+		return Argon2{}, &DecodeError{Field: "params", Value: vals[3], Err: err}
+	}
+
+	err = a.checkCost(o.maxCost)
+	if err != nil {
+		return Argon2{}, &DecodeError{Field: "params", Value: vals[3], Err: err}
+	}
+
+	return a, nil
+}
+
+// scaleMemory converts an amount of memory in the given unit to KiB, reporting false if it overflows.
+func scaleMemory(n uint32, u Unit) (uint32, bool) {
+	m := uint64(n) * uint64(u)
+	if m > math.MaxUint32 {
+		return 0, false
+	}
+
+	return uint32(m), true
+}
+
+// checkDigestLength verifies that the length of the decoded hashed value is within the configured range.
+func checkDigestLength(encoded string, n int, o options) error {
+	if n < int(o.minDigestLength) || n > int(o.maxDigestLength) {
+		return &DecodeError{
+			Field: "hash",
+			Value: encoded,
+			Err: fmt.Errorf(
+				"%w: the hashed value is %d bytes long, expected between %d and %d; it may have been truncated",
+				ErrInvalidEncodedHash,
+				n,
+				o.minDigestLength,
+				o.maxDigestLength,
+			),
+		}
+	}
+
+	return nil
+}
+
+// parseVersion parses the version segment of an encoded hash, e.g. "v=19".
+func parseVersion(s string) (int, error) {
+	key, val, ok := strings.Cut(s, "=")
+	if !ok || key != "v" {
+		return 0, fmt.Errorf("%w: malformed version", ErrInvalidEncodedHash)
+	}
+
+	version, err := strconv.Atoi(val)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("%w: malformed version", ErrInvalidEncodedHash)
+	}
+
+	return version, nil
+}
+
+// costCeiling returns the maximum cost accepted when comparing against the hash.
+func (a Argon2) costCeiling() Params {
+	if a.maxCost == (Params{}) {
+		return defaultMaxCost
+	}
+
+	return a.maxCost
+}
+
+// checkCost verifies that the decoded parameters do not exceed the given maximum cost.
+func (a Argon2) checkCost(maxCost Params) error {
+	switch {
+	case a.memory > maxCost.Memory:
+		return fmt.Errorf("%w: memory is %d KiB, maximum is %d KiB", ErrCostExceeded, a.memory, maxCost.Memory)
+	case a.iterations > maxCost.Iterations:
+		return fmt.Errorf("%w: iterations are %d, maximum is %d", ErrCostExceeded, a.iterations, maxCost.Iterations)
+	case a.parallelism > maxCost.Parallelism:
+		return fmt.Errorf("%w: parallelism is %d, maximum is %d", ErrCostExceeded, a.parallelism, maxCost.Parallelism)
+	}
+
+	return nil
+}
+
+// checkBounds verifies that the decoded parameters are within the bounds argon2 can compute.
+func (a Argon2) checkBounds() error {
+	switch {
+	case a.parallelism < 1:
+		return fmt.Errorf("%w: parallelism must be at least 1", ErrInvalidEncodedHash)
+	case a.iterations < 1:
+		return fmt.Errorf("%w: iterations must be at least 1", ErrInvalidEncodedHash)
+	case a.memory < minMemoryPerLane*uint32(a.parallelism):
+		return fmt.Errorf(
+			"%w: memory must be at least %d KiB per unit of parallelism, got %d KiB for %d",
+			ErrInvalidEncodedHash,
+			minMemoryPerLane,
+			a.memory,
+			a.parallelism,
+		)
+	case len(a.salt) == 0:
+		return fmt.Errorf("%w: the salt is empty", ErrInvalidEncodedHash)
+	}
+
+	return nil
+}
+
+// decodeBase64 decodes a standard or URL-safe base64 value, with or without padding.
+func decodeBase64(s string) ([]byte, error) {
+	b := make([]byte, base64.RawStdEncoding.DecodedLen(len(strings.TrimRight(s, "="))))
+
+	n, err := decodeBase64Into(b, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return b[:n], nil
+}
+
+// decodeBase64Into decodes a standard or URL-safe base64 value, with or without padding, into dst.
+//
+// The dst must be at least base64.RawStdEncoding.DecodedLen bytes long for the unpadded value.
+func decodeBase64Into(dst []byte, s string) (int, error) {
+	s = strings.TrimRight(s, "=")
+
+	n, err := base64.RawStdEncoding.Decode(dst, []byte(s))
+	if err != nil {
+		var urlErr error
+		if n, urlErr = base64.RawURLEncoding.Decode(dst, []byte(s)); urlErr != nil {
+			return 0, fmt.Errorf("%w: %s", ErrInvalidEncodedHash, err)
+		}
+	}
+
+	return n, nil
+}
+
+// wrongSeparator detects an encoded hash that uses a common wrong top-level separator instead of "$".
+func wrongSeparator(encoded string) (string, bool) {
+	if strings.Contains(encoded, "$") {
+		return "", false
+	}
+
+	for _, sep := range []string{":", ","} {
+		fields := strings.Split(strings.TrimPrefix(encoded, sep), sep)
+		if len(fields) > 1 && strings.HasPrefix(fields[0], "argon2") {
+			return sep, true
+		}
+	}
+
+	return "", false
+}
+
+// Verify reports whether the given password matches the given encoded hash.
+//
+// It behaves like argon2.CompareEncoded, but decodes the hashed value into a fixed-size buffer and
+// compares it with a single derived key, without constructing an intermediate Argon2 for the comparison.
+// Prefer it on hot paths that only need a yes-or-no answer for a stored hash.
+//
+// No package-wide lock is held while the key is derived, so concurrent calls, e.g. for hashes with a high
+// memory cost, run in parallel, each bounded by its own parameters.
+func Verify(encoded, password string) (bool, error) {
+	o, err := newOptions(nil)
+	if err != nil {
+		return false, err
+	}
+
+	vals, err := splitEncoded(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	a, err := decodeHeader(vals, o)
+	if err != nil {
+		return false, err
+	}
+
+	var buf [maxDigestLength]byte
+
+	if n := base64.RawStdEncoding.DecodedLen(len(strings.TrimRight(vals[5], "="))); n > len(buf) {
+		return false, checkDigestLength(vals[5], n, o)
+	}
+
+	n, err := decodeBase64Into(buf[:], vals[5])
+	if err != nil {
+		return false, &DecodeError{Field: "hash", Value: vals[5], Err: err}
+	}
+
+	err = checkDigestLength(vals[5], n, o)
+	if err != nil {
+		return false, err
+	}
+
+	a.keyLength = uint32(n)
+
+	err = a.compareDigest([]byte(password), buf[:n])
+	if errors.Is(err, ErrMismatched) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareEncoded reports whether the given password matches the given encoded hash.
+//
+// A mismatch is reported as false with no error; an error is returned if the hash cannot be decoded
+// or compared.
+func CompareEncoded(encoded, password string) (bool, error) {
+	o, err := newOptions(nil)
+	if err != nil {
+		return false, err
+	}
+
+	return compareEncoded(encoded, password, o)
+}
+
+func compareEncoded(encoded, password string, o options) (bool, error) {
+	a, err := newByEncoded(encoded, o)
+	if err != nil {
+		return false, err
+	}
+
+	err = a.Compare(password)
+	if errors.Is(err, ErrMismatched) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// BothMatch reports whether the given candidate verifies against both of the given encoded hashes.
+//
+// Each hash is verified using its own salt and parameters.
+func BothMatch(candidate string, encodedA, encodedB string) (bool, error) {
+	a, err := NewByEncoded(encodedA)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode the first hash: %w", err)
+	}
+
+	b, err := NewByEncoded(encodedB)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode the second hash: %w", err)
+	}
+
+	aErr := a.Compare(candidate)
+	bErr := b.Compare(candidate)
+
+	return aErr == nil && bErr == nil, nil
+}
+
+// Bytes generates random bytes of the given size.
+func Bytes(n uint32) ([]byte, error) {
+	return readBytes(rand.Reader, n)
+}
+
+// Token generates n random bytes and returns them encoded in URL-safe base64 without padding.
+//
+// It is meant for opaque tokens such as session identifiers or reset tokens, not for hashing.
+func Token(n uint32) (string, error) {
+	b, err := Bytes(n)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// readBytes reads exactly n bytes from the given reader.
+func readBytes(r io.Reader, n uint32) ([]byte, error) {
+	b := make([]byte, n)
+
+	_, err := io.ReadFull(r, b)
+	if err != nil {
+		return nil, randomSourceError{err: err}
+	}
+
+	return b, nil
+}
+
+// Salts generates n distinct salts of the given length using a single read of random bytes.
+//
+// The length must be greater than zero, and the salts must fit in 4 GiB in total.
+func Salts(n int, length uint32) ([][]byte, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	if length == 0 {
+		return nil, fmt.Errorf("%w: salt length must be greater than zero", ErrInvalidOption)
+	}
+
+	if uint64(n)*uint64(length) > math.MaxUint32 {
+		return nil, fmt.Errorf("%w: %d salts of %d bytes overflow the maximum size", ErrInvalidOption, n, length)
+	}
+
+	b, err := Bytes(uint32(n) * length)
+	if err != nil {
+		return nil, err
+	}
+
+	salts := make([][]byte, n)
+	seen := make(map[string]struct{}, n)
+
+	for i := range salts {
+		salt := b[uint32(i)*length : uint32(i+1)*length : uint32(i+1)*length]
+		if _, ok := seen[string(salt)]; ok {
+			return nil, fmt.Errorf("%w: generated salts collide", ErrInvalidSalt)
+		}
+
+		seen[string(salt)] = struct{}{}
+		salts[i] = salt
+	}
+
+	return salts, nil
 }
@@ -15,7 +15,16 @@
 package argon2_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/merajsahebdar/argon2"
 )
@@ -95,14 +104,1368 @@ func TestArgon2SQLScanner(t *testing.T) {
 	}
 
 	for idx, testCase := range testCases {
-		a := &argon2.Argon2{}
+		for _, src := range []interface{}{testCase.args, []byte(testCase.args)} {
+			a := &argon2.Argon2{}
 
-		if err := a.Scan(testCase.args); err != nil {
+			if err := a.Scan(src); err != nil {
+				t.Errorf("in case %d failed to decode %T: %s", idx, src, err)
+			} else {
+				if compareErr := a.Compare(testCase.want); compareErr != nil {
+					t.Errorf("in case %d failed to match %T", idx, src)
+				}
+			}
+		}
+	}
+
+	a := &argon2.Argon2{}
+	if err := a.Scan(42); !errors.Is(err, argon2.ErrScan) {
+		t.Errorf("expected ErrScan on an unsupported type, got %v", err)
+	}
+}
+
+func TestArgon2NewWithEntropy(t *testing.T) {
+	entropy := []byte("0123456789abcdef")
+
+	testCases := []struct {
+		args string
+		opts []argon2.Option
+	}{
+		{"password", nil},
+		{"secret", []argon2.Option{argon2.WithSaltLength(16)}},
+	}
+
+	for idx, testCase := range testCases {
+		a, err := argon2.NewWithEntropy(testCase.args, entropy, testCase.opts...)
+		if err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+
+			continue
+		}
+
+		b, err := argon2.NewWithEntropy(testCase.args, entropy, testCase.opts...)
+		if err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+
+			continue
+		}
+
+		if a.Encode() != b.Encode() {
+			t.Errorf("in case %d expected identical encoded hashes, got %s and %s", idx, a, b)
+		}
+	}
+
+	_, err := argon2.NewWithEntropy("password", entropy, argon2.WithSaltLength(8))
+	if !errors.Is(err, argon2.ErrInvalidSalt) {
+		t.Errorf("expected ErrInvalidSalt on entropy length mismatch, got %v", err)
+	}
+}
+
+func TestArgon2BothMatch(t *testing.T) {
+	encodedA := argon2.MustNew("password").Encode()
+	encodedB := argon2.MustNew("password").Encode()
+
+	testCases := []struct {
+		args string
+		want bool
+	}{
+		{"password", true},
+		{"other", false},
+	}
+
+	for idx, testCase := range testCases {
+		got, err := argon2.BothMatch(testCase.args, encodedA, encodedB)
+		if err != nil {
+			t.Errorf("in case %d error is not expected: %s", idx, err)
+
+			continue
+		}
+
+		if got != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, got)
+		}
+	}
+
+	if _, err := argon2.BothMatch("password", encodedA, "malformed"); !errors.Is(err, argon2.ErrInvalidEncodedHash) {
+		t.Errorf("expected ErrInvalidEncodedHash on a malformed hash, got %v", err)
+	}
+}
+
+func TestArgon2MaxVerifyMemory(t *testing.T) {
+	a, err := argon2.New("password", argon2.WithMaxVerifyMemory(128*1024))
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	b, err := argon2.NewByEncoded(a.Encode())
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if b.MaxVerifyMemory() != 128*1024 {
+		t.Errorf("expected a budget of %d, got %d", 128*1024, b.MaxVerifyMemory())
+	}
+
+	if compareErr := b.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match: %s", compareErr)
+	}
+
+	tampered := strings.Replace(a.Encode(), "m=65536", "m=262144", 1)
+
+	c, err := argon2.NewByEncoded(tampered)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if compareErr := c.Compare("password"); !errors.Is(compareErr, argon2.ErrMemoryBudgetExceeded) {
+		t.Errorf("expected ErrMemoryBudgetExceeded, got %v", compareErr)
+	}
+}
+
+func TestArgon2Salts(t *testing.T) {
+	testCases := []struct {
+		n      int
+		length uint32
+	}{
+		{1, 16},
+		{64, 16},
+		{10, 8},
+	}
+
+	for idx, testCase := range testCases {
+		salts, err := argon2.Salts(testCase.n, testCase.length)
+		if err != nil {
+			t.Errorf("in case %d failed to generate: %s", idx, err)
+
+			continue
+		}
+
+		if len(salts) != testCase.n {
+			t.Errorf("in case %d expected %d salts, got %d", idx, testCase.n, len(salts))
+		}
+
+		seen := make(map[string]bool, len(salts))
+		for _, salt := range salts {
+			if uint32(len(salt)) != testCase.length {
+				t.Errorf("in case %d expected salt length %d, got %d", idx, testCase.length, len(salt))
+			}
+
+			if seen[string(salt)] {
+				t.Errorf("in case %d got duplicate salts", idx)
+			}
+
+			seen[string(salt)] = true
+		}
+	}
+
+	invalid := []struct {
+		n      int
+		length uint32
+	}{
+		{3, 0},
+		{65537, 65536},
+		{65536, 65536},
+	}
+
+	for idx, testCase := range invalid {
+		if _, err := argon2.Salts(testCase.n, testCase.length); !errors.Is(err, argon2.ErrInvalidOption) {
+			t.Errorf("in case %d expected ErrInvalidOption, got %v", idx, err)
+		}
+	}
+}
+
+func TestToken(t *testing.T) {
+	for idx, n := range []uint32{0, 1, 16, 32} {
+		a, err := argon2.Token(n)
+		if err != nil {
+			t.Errorf("in case %d failed to generate: %s", idx, err)
+
+			continue
+		}
+
+		b, err := base64.RawURLEncoding.DecodeString(a)
+		if err != nil {
+			t.Errorf("in case %d expected a URL-safe base64 token, got %s", idx, a)
+		}
+
+		if len(b) != int(n) {
+			t.Errorf("in case %d expected %d bytes, got %d", idx, n, len(b))
+		}
+
+		if other, _ := argon2.Token(n); n >= 16 && other == a {
+			t.Errorf("in case %d expected distinct tokens, got %s twice", idx, a)
+		}
+	}
+}
+
+func BenchmarkSalts(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := argon2.Salts(100, 16); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSaltsSeparateBytes(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 100; j++ {
+			if _, err := argon2.Bytes(16); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestArgon2WrongSeparator(t *testing.T) {
+	testCases := []struct {
+		args string
+		want error
+	}{
+		{
+			":argon2id:v=19:m=65536,t=3,p=2:WDlCUU15WlF4OFNGd3d6OA:0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			argon2.ErrWrongSeparator,
+		},
+		{
+			"argon2id:v=19:m=65536,t=3,p=2:WDlCUU15WlF4OFNGd3d6OA:0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			argon2.ErrWrongSeparator,
+		},
+		{
+			"not a hash",
+			argon2.ErrInvalidEncodedHash,
+		},
+	}
+
+	for idx, testCase := range testCases {
+		if _, err := argon2.NewByEncoded(testCase.args); !errors.Is(err, testCase.want) {
+			t.Errorf("in case %d expected %v, got %v", idx, testCase.want, err)
+		}
+	}
+}
+
+func TestArgon2CompareConstant(t *testing.T) {
+	testCases := []struct {
+		deps argon2.Argon2
+		args string
+		want bool
+	}{
+		{argon2.MustNew("password"), "password", true},
+		{argon2.MustNew("password"), "secret", false},
+		{argon2.Argon2{}, "password", false},
+	}
+
+	for idx, testCase := range testCases {
+		start := time.Now()
+		got := testCase.deps.CompareConstant(testCase.args)
+		elapsed := time.Since(start)
+
+		if got != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, got)
+		}
+
+		if elapsed < time.Millisecond {
+			t.Errorf("in case %d expected a derivation to take place, took %s", idx, elapsed)
+		}
+	}
+}
+
+func TestArgon2MemoryUnit(t *testing.T) {
+	encoded := "$argon2id$v=19$m=64,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	a, err := argon2.NewByEncoded(encoded, argon2.WithMemoryUnit(argon2.MiB))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if !strings.Contains(a.Encode(), "m=65536,") {
+		t.Errorf("expected memory of 65536 KiB, got %s", a)
+	}
+
+	if compareErr := a.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match in MiB mode")
+	}
+
+	b, err := argon2.NewByEncoded(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if compareErr := b.Compare("password"); compareErr == nil {
+		t.Errorf("expected the literal interpretation to not match")
+	}
+
+	c, err := argon2.NewByEncoded(strings.Replace(encoded, "p=2", "p=2,budget=64", 1), argon2.WithMemoryUnit(argon2.MiB))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if got := c.MaxVerifyMemory(); got != 65536 {
+		t.Errorf("expected a budget of 65536 KiB, got %d", got)
+	}
+
+	if compareErr := c.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match within the budget in MiB mode: %v", compareErr)
+	}
+}
+
+func TestArgon2OnCompareFail(t *testing.T) {
+	a := argon2.MustNew("password")
+
+	var calls []string
+
+	argon2.OnCompareFail = func(encoded string) {
+		calls = append(calls, encoded)
+	}
+	defer func() {
+		argon2.OnCompareFail = nil
+	}()
+
+	if compareErr := a.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+
+	if compareErr := a.Compare("secret"); !errors.Is(compareErr, argon2.ErrMismatched) {
+		t.Errorf("expected ErrMismatched, got %v", compareErr)
+	}
+
+	if len(calls) != 1 {
+		t.Fatalf("expected the hook to be called once, got %d calls", len(calls))
+	}
+
+	if want := "$argon2id$v=19$m=65536,t=3,p=2$[redacted]"; calls[0] != want {
+		t.Errorf("expected %s, got %s", want, calls[0])
+	}
+}
+
+func TestCompareAny(t *testing.T) {
+	password := mustNewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+	secret := mustNewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$parPWxJrAJEdk57bpMuCC/kLhKJV4EnMb8205SNrFUQ",
+	)
+	other := argon2.MustNew("other", argon2.WithMemory(1024), argon2.WithIterations(1))
+
+	testCases := []struct {
+		args   string
+		hashes []argon2.Argon2
+		want   bool
+	}{
+		{"password", []argon2.Argon2{secret, password, other}, true},
+		{"other", []argon2.Argon2{secret, password, other}, true},
+		{"wrong", []argon2.Argon2{secret, password, other}, false},
+		{"password", []argon2.Argon2{{}, secret}, false},
+		{"password", nil, false},
+	}
+
+	for idx, testCase := range testCases {
+		if got := argon2.CompareAny(testCase.args, testCase.hashes); got != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, got)
+		}
+	}
+}
+
+func TestArgon2CompareAndDerive(t *testing.T) {
+	a, err := argon2.NewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	info := []byte("session")
+
+	ok, first := a.CompareAndDerive("password", info)
+	if !ok || len(first) == 0 {
+		t.Fatalf("expected a match with a derived value")
+	}
+
+	_, second := a.CompareAndDerive("password", info)
+	if !bytes.Equal(first, second) {
+		t.Errorf("expected a stable derived value")
+	}
+
+	_, other := a.CompareAndDerive("password", []byte("other"))
+	if bytes.Equal(first, other) {
+		t.Errorf("expected a different derived value for different info")
+	}
+
+	ok, derived := a.CompareAndDerive("secret", info)
+	if ok || derived != nil {
+		t.Errorf("expected no match and a nil derived value, got %t and %x", ok, derived)
+	}
+}
+
+func TestArgon2CompareDerived(t *testing.T) {
+	entropy := []byte("0123456789abcdef")
+
+	stored, err := argon2.NewWithEntropy("password", entropy)
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	testCases := []struct {
+		args    string
+		entropy []byte
+		want    bool
+	}{
+		{"password", entropy, true},
+		{"secret", entropy, false},
+		{"password", []byte("fedcba9876543210"), false},
+	}
+
+	for idx, testCase := range testCases {
+		candidate, err := argon2.NewWithEntropy(testCase.args, testCase.entropy)
+		if err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+
+			continue
+		}
+
+		if got := stored.CompareDerived(candidate); got != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, got)
+		}
+	}
+}
+
+func TestArgon2SaltAndHash(t *testing.T) {
+	testCases := []argon2.Argon2{
+		argon2.MustNew("password"),
+		argon2.MustNew("secret", argon2.WithSaltLength(8), argon2.WithKeyLength(16)),
+	}
+
+	for idx, a := range testCases {
+		segments := strings.Split(a.Encode(), "$")
+
+		salt, err := base64.RawStdEncoding.DecodeString(segments[4])
+		if err != nil {
+			t.Fatalf("in case %d failed to decode the salt: %s", idx, err)
+		}
+
+		hash, err := base64.RawStdEncoding.DecodeString(segments[5])
+		if err != nil {
+			t.Fatalf("in case %d failed to decode the hash: %s", idx, err)
+		}
+
+		if !bytes.Equal(a.Salt(), salt) {
+			t.Errorf("in case %d expected the salt %x, got %x", idx, salt, a.Salt())
+		}
+
+		if !bytes.Equal(a.Hash(), hash) {
+			t.Errorf("in case %d expected the hash %x, got %x", idx, hash, a.Hash())
+		}
+
+		a.Salt()[0] ^= 0xff
+		a.Hash()[0] ^= 0xff
+
+		if !bytes.Equal(a.Salt(), salt) || !bytes.Equal(a.Hash(), hash) {
+			t.Errorf("in case %d expected the hash not to be affected by mutating the copies", idx)
+		}
+	}
+
+	if (argon2.Argon2{}).Salt() != nil || (argon2.Argon2{}).Hash() != nil {
+		t.Error("expected nil for an invalid hash")
+	}
+}
+
+func TestArgon2DecodeErrorField(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	testCases := []struct {
+		old   string
+		new   string
+		field string
+		value string
+	}{
+		{"$argon2id", "x$argon2id", "prefix", "x"},
+		{"argon2id", "argon2x", "variant", "argon2x"},
+		{"v=19", "v=1x", "version", "v=1x"},
+		{"t=3", "t=x", "params", "m=65536,t=x,p=2"},
+		{"WDlCUU15WlF4OFNGd3d6OA", "WDlCUU15WlF4OFNGd3d6O!", "salt", "WDlCUU15WlF4OFNGd3d6O!"},
+		{"2w3nnI8", "2w3nnI!", "hash", "0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI!"},
+	}
+
+	for idx, testCase := range testCases {
+		_, err := argon2.NewByEncoded(strings.Replace(encoded, testCase.old, testCase.new, 1))
+
+		var decodeErr *argon2.DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Errorf("in case %d expected a DecodeError, got %v", idx, err)
+
+			continue
+		}
+
+		if decodeErr.Field != testCase.field || decodeErr.Value != testCase.value {
+			t.Errorf(
+				"in case %d expected %s %q, got %s %q",
+				idx,
+				testCase.field,
+				testCase.value,
+				decodeErr.Field,
+				decodeErr.Value,
+			)
+		}
+
+		if !errors.Is(err, argon2.ErrInvalidEncodedHash) {
+			t.Errorf("in case %d expected the error to match ErrInvalidEncodedHash", idx)
+		}
+
+		if !strings.Contains(err.Error(), testCase.field) || !strings.Contains(err.Error(), testCase.value) {
+			t.Errorf("in case %d expected the error to name the %s, got %s", idx, testCase.field, err)
+		}
+	}
+}
+
+func TestArgon2ErrorSentinels(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	testCases := []struct {
+		old     string
+		new     string
+		wantErr error
+	}{
+		{"WDlCUU15WlF4OFNGd3d6OA", "WDlCUQ", argon2.ErrInvalidSalt},
+		{"WDlCUU15WlF4OFNGd3d6OA", "WDlCUU15WlF4OFNGd3d6O!", argon2.ErrInvalidSalt},
+		{"2w3nnI8", "2w3nnI!", argon2.ErrInvalidDigest},
+		{"wcd+cG4er9wu3DgYCBJb2w3nnI8", "", argon2.ErrInvalidDigest},
+		{"t=3", "t=x", argon2.ErrInvalidParams},
+		{"p=2", "p=2,x=1", argon2.ErrInvalidParams},
+		{"m=65536", "m=1", argon2.ErrInvalidParams},
+	}
+
+	for idx, testCase := range testCases {
+		_, err := argon2.NewByEncoded(strings.Replace(encoded, testCase.old, testCase.new, 1))
+		if !errors.Is(err, testCase.wantErr) {
+			t.Errorf("in case %d expected %v, got %v", idx, testCase.wantErr, err)
+		}
+
+		if !errors.Is(err, argon2.ErrInvalidEncodedHash) {
+			t.Errorf("in case %d expected the error to match ErrInvalidEncodedHash", idx)
+		}
+	}
+
+	if _, err := argon2.New("password", argon2.WithRandReader(failingReader{})); !errors.Is(err, argon2.ErrRandomSource) {
+		t.Errorf("expected ErrRandomSource, got %v", err)
+	}
+
+	_, err := argon2.New("password", argon2.WithRandReader(bytes.NewReader([]byte("short"))))
+	if !errors.Is(err, argon2.ErrRandomSource) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected ErrRandomSource wrapping io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestArgon2Valid(t *testing.T) {
+	if !argon2.MustNew("password").Valid() {
+		t.Error("expected a created hash to be valid")
+	}
+
+	if a, err := argon2.NewByEncoded("malformed"); err == nil || a.Valid() {
+		t.Error("expected a failed decoding to result in an invalid hash")
+	}
+
+	var a argon2.Argon2
+	if a.Valid() {
+		t.Error("expected the zero value to be invalid")
+	}
+
+	for idx, args := range []string{"", "password"} {
+		if compareErr := a.Compare(args); !errors.Is(compareErr, argon2.ErrInvalid) {
+			t.Errorf("in case %d expected ErrInvalid, got %v", idx, compareErr)
+		}
+	}
+}
+
+func TestArgon2CompareErr(t *testing.T) {
+	a := mustNewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+
+	testCases := []struct {
+		hash    argon2.Argon2
+		args    string
+		want    bool
+		wantErr error
+	}{
+		{a, "password", true, nil},
+		{a, "secret", false, nil},
+		{argon2.Argon2{}, "password", false, argon2.ErrInvalid},
+		{argon2.Argon2{}, "", false, argon2.ErrInvalid},
+	}
+
+	for idx, testCase := range testCases {
+		got, err := testCase.hash.CompareErr(testCase.args)
+		if !errors.Is(err, testCase.wantErr) {
+			t.Errorf("in case %d expected error %v, got %v", idx, testCase.wantErr, err)
+		}
+
+		if got != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	testCases := []struct {
+		args    string
+		wantErr error
+	}{
+		{encoded, nil},
+		{"$argon2i$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$LemjSGlZG4wIF14JADA5jkdoISphpCdrnpBJdv+BEOM", nil},
+		{legacyEncoded, nil},
+		{strings.Replace(encoded, "v=19", "v=99", 1), nil},
+		{strings.Replace(encoded, "$v=19", "", 1), argon2.ErrInvalidEncodedHash},
+		{strings.Replace(encoded, "WDlCUU15WlF4OFNGd3d6OA", "WDlCUU15W!F4OFNGd3d6OA", 1), argon2.ErrInvalidEncodedHash},
+		{strings.Replace(encoded, "argon2id", "bcrypt", 1), argon2.ErrInvalidEncodedHash},
+		{strings.Replace(encoded, "argon2id", "argon2d", 1), argon2.ErrUnsupportedVariant},
+		{strings.Replace(encoded, "p=2", "p=0", 1), argon2.ErrInvalidEncodedHash},
+	}
+
+	for idx, testCase := range testCases {
+		err := argon2.Validate(testCase.args)
+		if testCase.wantErr == nil && err != nil {
+			t.Errorf("in case %d expected a valid hash, got %s", idx, err)
+		} else if !errors.Is(err, testCase.wantErr) {
+			t.Errorf("in case %d expected %v, got %v", idx, testCase.wantErr, err)
+		}
+	}
+}
+
+func TestMustNewByEncoded(t *testing.T) {
+	a := argon2.MustNewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+	if compareErr := a.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !errors.Is(err, argon2.ErrInvalidEncodedHash) {
+			t.Errorf("expected a panic wrapping ErrInvalidEncodedHash, got %v", err)
+		}
+	}()
+
+	argon2.MustNewByEncoded("malformed")
+}
+
+func TestArgon2ConcurrentCompare(t *testing.T) {
+	a := argon2.MustNew("password", argon2.WithMemory(1024), argon2.WithIterations(1))
+	encoded := a.Encode()
+
+	var wg sync.WaitGroup
+
+	for idx := 0; idx < 16; idx++ {
+		wg.Add(1)
+
+		go func(idx int) {
+			defer wg.Done()
+
+			candidate, want := "password", error(nil)
+			if idx%2 == 1 {
+				candidate, want = "secret", argon2.ErrMismatched
+			}
+
+			if compareErr := a.Compare(candidate); !errors.Is(compareErr, want) {
+				t.Errorf("in goroutine %d expected %v, got %v", idx, want, compareErr)
+			}
+
+			if a.Encode() != encoded {
+				t.Errorf("in goroutine %d expected %s, got %s", idx, encoded, a)
+			}
+
+			if v, err := a.Value(); err != nil || v != encoded {
+				t.Errorf("in goroutine %d expected %s, got %v, %v", idx, encoded, v, err)
+			}
+		}(idx)
+	}
+
+	wg.Wait()
+}
+
+func TestArgon2DigestLength(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	testCases := []struct {
+		args    string
+		opts    []argon2.Option
+		wantErr bool
+	}{
+		{encoded, nil, false},
+		{encoded[:len(encoded)-24], nil, true},
+		{encoded + encoded[len(encoded)-43:] + "AA", nil, true},
+		{encoded[:len(encoded)-24], []argon2.Option{argon2.WithDigestLengthRange(8, 64)}, false},
+		{encoded, []argon2.Option{argon2.WithDigestLengthRange(8, 16)}, true},
+	}
+
+	for idx, testCase := range testCases {
+		_, err := argon2.NewByEncoded(testCase.args, testCase.opts...)
+		if testCase.wantErr && !errors.Is(err, argon2.ErrInvalidEncodedHash) {
+			t.Errorf("in case %d expected ErrInvalidEncodedHash, got %v", idx, err)
+		} else if !testCase.wantErr && err != nil {
 			t.Errorf("in case %d failed to decode: %s", idx, err)
-		} else {
-			if compareErr := a.Compare(testCase.want); compareErr != nil {
-				t.Errorf("in case %d failed to match", idx)
+		}
+	}
+
+	if _, err := argon2.NewByEncoded(encoded, argon2.WithDigestLengthRange(32, 16)); err == nil {
+		t.Errorf("expected an error on an empty range")
+	}
+}
+
+func TestArgon2MinSaltLength(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+	short := strings.Replace(encoded, "WDlCUU15WlF4OFNGd3d6OA", "WDlCUQ", 1)
+
+	testCases := []struct {
+		args    string
+		opts    []argon2.Option
+		want    int
+		wantErr bool
+	}{
+		{encoded, nil, 16, false},
+		{short, nil, 0, true},
+		{short, []argon2.Option{argon2.WithMinSaltLength(4)}, 4, false},
+		{encoded, []argon2.Option{argon2.WithMinSaltLength(32)}, 0, true},
+	}
+
+	for idx, testCase := range testCases {
+		a, err := argon2.NewByEncoded(testCase.args, testCase.opts...)
+		if testCase.wantErr && !errors.Is(err, argon2.ErrInvalidSalt) {
+			t.Errorf("in case %d expected ErrInvalidSalt, got %v", idx, err)
+		} else if !testCase.wantErr && err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+		}
+
+		if got := a.SaltLength(); got != testCase.want {
+			t.Errorf("in case %d expected a salt length of %d, got %d", idx, testCase.want, got)
+		}
+	}
+
+	if _, err := argon2.NewByEncoded(encoded, argon2.WithMinSaltLength(0)); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestArgon2MinSaltLengthOnCreate(t *testing.T) {
+	short := []argon2.Option{argon2.WithMemory(64), argon2.WithIterations(1), argon2.WithSaltLength(4)}
+
+	if _, err := argon2.New("password", short...); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption for a short salt length, got %v", err)
+	}
+
+	if _, err := argon2.New("password", argon2.WithSalt([]byte("salt"))); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption for a short salt, got %v", err)
+	}
+
+	if _, err := argon2.NewHasher(short...); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption for a hasher, got %v", err)
+	}
+
+	params := argon2.Defaults()
+	params.SaltLength = 4
+
+	if err := argon2.SetDefaults(params); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption for the defaults, got %v", err)
+	}
+
+	a, err := argon2.New("password", append(short, argon2.WithMinSaltLength(4))...)
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if _, err = argon2.NewByEncoded(a.Encode(), argon2.WithMinSaltLength(4)); err != nil {
+		t.Errorf("failed to decode: %s", err)
+	}
+}
+
+func TestArgon2MaxCost(t *testing.T) {
+	testCases := []struct {
+		args    string
+		opts    []argon2.Option
+		wantErr bool
+	}{
+		{"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8", nil, false},
+		{"$argon2id$v=19$m=4194304,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8", nil, true},
+		{"$argon2id$v=19$m=65536,t=1000,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8", nil, true},
+		{"$argon2id$v=19$m=65536,t=3,p=255$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8", nil, true},
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			[]argon2.Option{argon2.WithMaxCost(argon2.Params{Memory: 32 * 1024, Iterations: 3, Parallelism: 2})},
+			true,
+		},
+		{
+			"$argon2id$v=19$m=4194304,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			[]argon2.Option{argon2.WithMaxCost(argon2.Params{Memory: 4 * 1024 * 1024, Iterations: 3, Parallelism: 2})},
+			false,
+		},
+	}
+
+	for idx, testCase := range testCases {
+		_, err := argon2.NewByEncoded(testCase.args, testCase.opts...)
+		if testCase.wantErr && !errors.Is(err, argon2.ErrCostExceeded) {
+			t.Errorf("in case %d expected ErrCostExceeded, got %v", idx, err)
+		} else if !testCase.wantErr && err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+		}
+	}
+
+	if _, err := argon2.NewByEncoded("", argon2.WithMaxCost(argon2.Params{})); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestArgon2CompareMaxCost(t *testing.T) {
+	a := mustNewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+
+	testCases := []argon2.Argon2{
+		a.WithCost(4000000000, 1, 1),
+		a.WithCost(64, 1000000, 1),
+		a.WithCost(64*255, 1, 255),
+	}
+
+	for idx, testCase := range testCases {
+		if compareErr := testCase.Compare("password"); !errors.Is(compareErr, argon2.ErrCostExceeded) {
+			t.Errorf("in case %d expected ErrCostExceeded, got %v", idx, compareErr)
+		}
+
+		if argon2.CompareAny("password", []argon2.Argon2{testCase}) {
+			t.Errorf("in case %d expected no match", idx)
+		}
+	}
+
+	b, err := argon2.New("password", argon2.WithMemory(64), argon2.WithIterations(65), argon2.WithParallelism(1))
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if compareErr := b.Compare("password"); compareErr != nil {
+		t.Errorf("expected a created hash to be compared at its own cost, got %v", compareErr)
+	}
+
+	maxCost := argon2.Params{Memory: 64, Iterations: 65, Parallelism: 1}
+
+	c, err := argon2.NewByEncoded(b.Encode(), argon2.WithMaxCost(maxCost))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if compareErr := c.Compare("password"); compareErr != nil {
+		t.Errorf("expected a decoded hash to be compared within the configured maximum cost, got %v", compareErr)
+	}
+}
+
+func TestArgon2AssociatedData(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2,data=YXNzb2NpYXRlZA" +
+		"$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	a, err := argon2.NewByEncoded(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if a.Encode() != encoded {
+		t.Errorf("expected %s, got %s", encoded, a)
+	}
+
+	if compareErr := a.Compare("password"); !errors.Is(compareErr, argon2.ErrAssociatedData) {
+		t.Errorf("expected ErrAssociatedData, got %v", compareErr)
+	}
+
+	if a.Equal(mustNewByEncoded(strings.Replace(encoded, ",data=YXNzb2NpYXRlZA", "", 1))) {
+		t.Errorf("expected hashes with distinct associated data not to be equal")
+	}
+
+	if _, err = argon2.NewByEncoded(strings.Replace(encoded, "YXNzb2NpYXRlZA", "!", 1)); err == nil {
+		t.Errorf("expected an error on malformed associated data")
+	}
+}
+
+func TestArgon2KeyID(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2,keyid=a2V5MQ" +
+		"$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	a, err := argon2.NewByEncoded(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if a.Encode() != encoded {
+		t.Errorf("expected %s, got %s", encoded, a)
+	}
+
+	if compareErr := a.Compare("password"); compareErr != nil {
+		t.Errorf("expected the key id not to take part in the comparison, got %v", compareErr)
+	}
+
+	if a.Equal(mustNewByEncoded(strings.Replace(encoded, ",keyid=a2V5MQ", "", 1))) {
+		t.Errorf("expected hashes with distinct key ids not to be equal")
+	}
+
+	if _, err = a.MarshalBinary(); !errors.Is(err, argon2.ErrInvalidParams) {
+		t.Errorf("expected error %v, got %v", argon2.ErrInvalidParams, err)
+	}
+
+	withData := strings.Replace(encoded, "keyid=a2V5MQ", "keyid=a2V5MQ,data=YXNzb2NpYXRlZA", 1)
+	if got := mustNewByEncoded(withData).Encode(); got != withData {
+		t.Errorf("expected %s, got %s", withData, got)
+	}
+
+	if _, err = argon2.NewByEncoded(strings.Replace(encoded, "a2V5MQ", "!", 1)); !errors.Is(err, argon2.ErrInvalidParams) {
+		t.Errorf("expected an error on a malformed key id, got %v", err)
+	}
+}
+
+func TestArgon2StringRedacted(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+	a := mustNewByEncoded(encoded)
+
+	if v, err := a.Value(); err != nil || v != encoded {
+		t.Errorf("expected the value %s, got %v, %v", encoded, v, err)
+	}
+
+	if got := a.Encode(); got != encoded {
+		t.Errorf("expected the encoding %s, got %s", encoded, got)
+	}
+
+	want := "$argon2id$v=19$m=65536,t=3,p=2$[redacted]"
+
+	for idx, got := range []string{a.String(), fmt.Sprintf("%v", a), fmt.Sprint(a)} {
+		if got != want {
+			t.Errorf("in case %d expected %s, got %s", idx, want, got)
+		}
+	}
+}
+
+func TestArgon2GoString(t *testing.T) {
+	testCases := []struct {
+		args string
+		want string
+	}{
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			"argon2.Argon2{variant:argon2id, m:65536, t:3, p:2, keyLen:32, valid:true}",
+		},
+		{"malformed", "argon2.Argon2{valid:false}"},
+	}
+
+	for idx, testCase := range testCases {
+		a, _ := argon2.NewByEncoded(testCase.args)
+
+		got := fmt.Sprintf("%#v", a)
+		if got != testCase.want {
+			t.Errorf("in case %d expected %s, got %s", idx, testCase.want, got)
+		}
+
+		if strings.Contains(got, "WDlCUU15WlF4OFNGd3d6OA") || strings.Contains(got, "0nJpNUfEq3EL") {
+			t.Errorf("in case %d expected no salt or hashed value, got %s", idx, got)
+		}
+	}
+}
+
+func TestArgon2CompareRaw(t *testing.T) {
+	a := mustNewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+
+	flipped := a.Hash()
+	flipped[0] ^= 1
+
+	testCases := []struct {
+		hash argon2.Argon2
+		args []byte
+		want bool
+	}{
+		{a, a.Hash(), true},
+		{a, flipped, false},
+		{a, a.Hash()[:16], false},
+		{a, nil, false},
+		{argon2.Argon2{}, nil, false},
+	}
+
+	for idx, testCase := range testCases {
+		if got := testCase.hash.CompareRaw(testCase.args); got != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, got)
+		}
+	}
+}
+
+func TestArgon2Equal(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	testCases := []struct {
+		args string
+		want bool
+	}{
+		{encoded, true},
+		{"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OQ$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8", false},
+		{"$argon2id$v=19$m=65536,t=4,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8", false},
+		{"$argon2i$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8", false},
+		{"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$parPWxJrAJEdk57bpMuCC/kLhKJV4EnMb8205SNrFUQ", false},
+		{strings.Replace(encoded, "p=2", "p=2,budget=70000", 1), false},
+	}
+
+	a := mustNewByEncoded(encoded)
+
+	for idx, testCase := range testCases {
+		b := mustNewByEncoded(testCase.args)
+
+		if got := a.Equal(b); got != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, got)
+		}
+	}
+
+	if a.Equal(argon2.Argon2{}) {
+		t.Error("expected an invalid value not to be equal")
+	}
+}
+
+// legacyEncoded is the argon2i hash of "password" of version 16 from the test vectors of the reference
+// implementation, which golang.org/x/crypto/argon2 cannot compute.
+const legacyEncoded = "$argon2i$v=16$m=65536,t=2,p=1$c29tZXNhbHQ$9sTbSlTio3Biev89thdrlKKiCaYsjjYVJxGAL3swxpQ"
+
+func TestArgon2CompatibleVersions(t *testing.T) {
+	if _, err := argon2.NewByEncoded(legacyEncoded); !errors.Is(err, argon2.ErrIncompatibleVersion) {
+		t.Errorf("expected ErrIncompatibleVersion, got %v", err)
+	}
+
+	defaults := argon2.CompatibleVersions
+	argon2.CompatibleVersions = append([]int{16}, defaults...)
+
+	defer func() {
+		argon2.CompatibleVersions = defaults
+	}()
+
+	a, err := argon2.NewByEncoded(legacyEncoded)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if a.Encode() != legacyEncoded {
+		t.Errorf("expected %s, got %s", legacyEncoded, a)
+	}
+
+	if compareErr := a.Compare("password"); !errors.Is(compareErr, argon2.ErrIncompatibleVersion) {
+		t.Errorf("expected ErrIncompatibleVersion, got %v", compareErr)
+	}
+}
+
+func TestArgon2AllowedVersions(t *testing.T) {
+	a, err := argon2.NewByEncoded(legacyEncoded, argon2.WithAllowedVersions(16))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if a.Version() != 16 {
+		t.Errorf("expected version 16, got %d", a.Version())
+	}
+
+	if a.Encode() != legacyEncoded {
+		t.Errorf("expected %s, got %s", legacyEncoded, a)
+	}
+
+	failed := 0
+	argon2.OnCompareFail = func(string) {
+		failed++
+	}
+
+	defer func() {
+		argon2.OnCompareFail = nil
+	}()
+
+	for idx, args := range []string{"password", "secret"} {
+		compareErr := a.Compare(args)
+		if !errors.Is(compareErr, argon2.ErrIncompatibleVersion) || errors.Is(compareErr, argon2.ErrMismatched) {
+			t.Errorf("in case %d expected ErrIncompatibleVersion, got %v", idx, compareErr)
+		}
+	}
+
+	if failed != 0 {
+		t.Errorf("expected OnCompareFail not to be called, got %d calls", failed)
+	}
+
+	if argon2.CompareAny("password", []argon2.Argon2{a}) {
+		t.Errorf("expected no match")
+	}
+
+	if _, err = argon2.NewByEncoded(legacyEncoded); !errors.Is(err, argon2.ErrIncompatibleVersion) {
+		t.Errorf("expected the version to be allowed only for the given decoding, got %v", err)
+	}
+
+	if v := argon2.MustNew("password").Version(); v != 19 {
+		t.Errorf("expected version 19, got %d", v)
+	}
+
+	if v := (argon2.Argon2{}).Version(); v != 0 {
+		t.Errorf("expected version 0 for an invalid hash, got %d", v)
+	}
+}
+
+func TestArgon2Hash64(t *testing.T) {
+	a, err := argon2.NewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	b, err := argon2.NewByEncoded(a.Encode())
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	c, err := argon2.NewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$parPWxJrAJEdk57bpMuCC/kLhKJV4EnMb8205SNrFUQ",
+	)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	h := a.Hash64()
+
+	if h != a.Hash64() || h != b.Hash64() {
+		t.Errorf("expected identical hashes to have the same value")
+	}
+
+	if h == c.Hash64() {
+		t.Errorf("expected different hashes to have different values")
+	}
+}
+
+func TestArgon2ExternalSalt(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+
+	a, err := argon2.NewWithEntropy("password", salt)
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	vals := strings.Split(a.Encode(), "$")
+	vals[4] = ""
+	saltless := strings.Join(vals, "$")
+
+	if _, err := argon2.NewByEncoded(saltless); !errors.Is(err, argon2.ErrInvalidSalt) {
+		t.Errorf("expected ErrInvalidSalt without an external salt, got %v", err)
+	}
+
+	b, err := argon2.NewByEncoded(saltless, argon2.WithExternalSalt(salt))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if b.Encode() != saltless {
+		t.Errorf("expected %s, got %s", saltless, b)
+	}
+
+	if compareErr := b.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+
+	b.Wipe()
+
+	c, err := argon2.NewByEncoded(saltless, argon2.WithExternalSalt(salt))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if compareErr := c.Compare("password"); compareErr != nil {
+		t.Errorf("expected wiping a previous hash to leave the external salt intact")
+	}
+}
+
+func TestArgon2Bytes(t *testing.T) {
+	testCases := []struct {
+		args []byte
+		want string
+	}{
+		{[]byte("password"), "password"},
+		{[]byte{0xff, 0x00, 0xfe}, "\xff\x00\xfe"},
+	}
+
+	for idx, testCase := range testCases {
+		a, err := argon2.NewBytes(testCase.args)
+		if err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+
+			continue
+		}
+
+		if compareErr := a.Compare(testCase.want); compareErr != nil {
+			t.Errorf("in case %d failed to match as a string", idx)
+		}
+
+		b := argon2.MustNew(testCase.want)
+
+		if compareErr := b.CompareBytes(testCase.args); compareErr != nil {
+			t.Errorf("in case %d failed to match as bytes", idx)
+		}
+	}
+}
+
+func TestCompareEncoded(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	testCases := []struct {
+		encoded string
+		args    string
+		want    bool
+		wantErr error
+	}{
+		{encoded, "password", true, nil},
+		{encoded, "secret", false, nil},
+		{"malformed", "password", false, argon2.ErrInvalidEncodedHash},
+	}
+
+	for idx, testCase := range testCases {
+		got, err := argon2.CompareEncoded(testCase.encoded, testCase.args)
+		if !errors.Is(err, testCase.wantErr) {
+			t.Errorf("in case %d expected error %v, got %v", idx, testCase.wantErr, err)
+		}
+
+		if got != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, got)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	testCases := []struct {
+		encoded string
+		args    string
+	}{
+		{encoded, "password"},
+		{encoded, "secret"},
+		{strings.Replace(encoded, "argon2id", "argon2i", 1), "password"},
+		{encoded + "A", "password"},
+		{strings.Replace(encoded, "0nJpNUfEq3ELzeoG", "", 1), "password"},
+		{strings.Replace(encoded, "m=65536", "m=1", 1), "password"},
+		{"malformed", "password"},
+	}
+
+	for idx, testCase := range testCases {
+		got, err := argon2.Verify(testCase.encoded, testCase.args)
+
+		var want bool
+
+		a, wantErr := argon2.NewByEncoded(testCase.encoded)
+		if wantErr == nil {
+			wantErr = a.Compare(testCase.args)
+			want = wantErr == nil
+
+			if errors.Is(wantErr, argon2.ErrMismatched) {
+				wantErr = nil
+			}
+		}
+
+		if (err == nil) != (wantErr == nil) || (err != nil && err.Error() != wantErr.Error()) {
+			t.Errorf("in case %d expected error %v, got %v", idx, wantErr, err)
+		}
+
+		if got != want {
+			t.Errorf("in case %d expected %t, got %t", idx, want, got)
+		}
+	}
+}
+
+func TestVerifyAllocs(t *testing.T) {
+	a, err := argon2.New("password", argon2.WithMemory(16), argon2.WithIterations(1), argon2.WithParallelism(1))
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	encoded := a.Encode()
+
+	verify := testing.AllocsPerRun(10, func() {
+		if ok, verifyErr := argon2.Verify(encoded, "password"); !ok || verifyErr != nil {
+			t.Fatalf("failed to verify: %v", verifyErr)
+		}
+	})
+
+	compare := testing.AllocsPerRun(10, func() {
+		decoded, decodeErr := argon2.NewByEncoded(encoded)
+		if decodeErr != nil {
+			t.Fatalf("failed to decode: %s", decodeErr)
+		}
+
+		if compareErr := decoded.Compare("password"); compareErr != nil {
+			t.Fatalf("failed to compare: %s", compareErr)
+		}
+	})
+
+	if verify >= compare {
+		t.Errorf("expected Verify to allocate less than NewByEncoded and Compare, got %v and %v", verify, compare)
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	a, err := argon2.New("password", argon2.WithMemory(16), argon2.WithIterations(1), argon2.WithParallelism(1))
+	if err != nil {
+		b.Fatalf("failed to create: %s", err)
+	}
+
+	encoded := a.Encode()
+
+	b.Run("Verify", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if ok, verifyErr := argon2.Verify(encoded, "password"); !ok || verifyErr != nil {
+				b.Fatalf("failed to verify: %v", verifyErr)
+			}
+		}
+	})
+
+	b.Run("NewByEncodedCompare", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			decoded, decodeErr := argon2.NewByEncoded(encoded)
+			if decodeErr != nil {
+				b.Fatal(decodeErr)
+			}
+
+			if compareErr := decoded.Compare("password"); compareErr != nil {
+				b.Fatal(compareErr)
 			}
 		}
+	})
+}
+
+func TestArgon2NewContext(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := argon2.NewContext(cancelled, "password"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	generous, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	a, err := argon2.NewContext(generous, "password")
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if compareErr := a.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+}
+
+func TestArgon2DecodeBounds(t *testing.T) {
+	testCases := []string{
+		"$argon2id$v=19$m=65536,t=3,p=0$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+		"$argon2id$v=19$m=65536,t=0,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+		"$argon2id$v=19$m=0,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+		"$argon2id$v=19$m=15,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$",
+	}
+
+	for idx, testCase := range testCases {
+		if _, err := argon2.NewByEncoded(testCase); !errors.Is(err, argon2.ErrInvalidEncodedHash) {
+			t.Errorf("in case %d expected ErrInvalidEncodedHash, got %v", idx, err)
+		}
 	}
 }
@@ -0,0 +1,77 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"fmt"
+	"io"
+)
+
+// AuditIssue describes a line of a credential store that needs attention.
+type AuditIssue struct {
+	// Line is the line number, starting from 1.
+	Line int
+
+	// Problem describes why the line needs attention.
+	Problem string
+}
+
+// AuditReport summarizes the encoded hashes of a credential store.
+type AuditReport struct {
+	// Valid is the number of hashes that decode, including the ones that need a rehash.
+	Valid int
+
+	// NeedsRehash is the number of valid hashes created with parameters other than the target.
+	NeedsRehash int
+
+	// Corrupt is the number of lines that fail to decode.
+	Corrupt int
+
+	// Issues lists the lines that need a rehash or fail to decode, in order.
+	Issues []AuditIssue
+}
+
+// AuditFile reads encoded hashes from r, one per line, and reports how many decode, how many need a rehash
+// against the target parameters and how many are corrupt.
+//
+// Lines are read the same way as by argon2.DecodeAll. A corrupt line does not stop the audit; an error is
+// only returned if r cannot be read.
+func AuditFile(r io.Reader, target Params) (AuditReport, error) {
+	var report AuditReport
+
+	err := ForEachEncoded(r, func(lineNum int, a Argon2, err error) error {
+		switch {
+		case err != nil:
+			report.Corrupt++
+			report.Issues = append(report.Issues, AuditIssue{Line: lineNum, Problem: err.Error()})
+		case a.NeedsRehash(target):
+			report.Valid++
+			report.NeedsRehash++
+			report.Issues = append(report.Issues, AuditIssue{
+				Line:    lineNum,
+				Problem: fmt.Sprintf("needs rehash: created with %s", a.redacted()),
+			})
+		default:
+			report.Valid++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return AuditReport{}, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestAuditFile(t *testing.T) {
+	current := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+	weak := strings.Replace(current, "t=3", "t=1", 1)
+	content := current + "\r\n" + weak + "\n\nmalformed\n"
+
+	target := argon2.Params{
+		Variant:     argon2.VariantID,
+		Memory:      65536,
+		Iterations:  3,
+		Parallelism: 2,
+		KeyLength:   32,
+		SaltLength:  16,
+	}
+
+	report, err := argon2.AuditFile(strings.NewReader(content), target)
+	if err != nil {
+		t.Fatalf("failed to audit: %s", err)
+	}
+
+	if report.Valid != 2 || report.NeedsRehash != 1 || report.Corrupt != 1 {
+		t.Errorf("expected 2 valid, 1 needing a rehash and 1 corrupt, got %+v", report)
+	}
+
+	if len(report.Issues) != 2 || report.Issues[0].Line != 2 || report.Issues[1].Line != 4 {
+		t.Fatalf("expected issues on lines 2 and 4, got %+v", report.Issues)
+	}
+
+	if !strings.Contains(report.Issues[0].Problem, "rehash") {
+		t.Errorf("expected the weak hash to need a rehash, got %q", report.Issues[0].Problem)
+	}
+
+	if _, err = argon2.AuditFile(failingReader{}, target); err == nil {
+		t.Errorf("expected the read error to be returned")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"fmt"
+	"time"
+)
+
+// calibrationStartMemory is the memory in KiB argon2.Calibrate starts from.
+const calibrationStartMemory = 1024
+
+// Calibrate returns the heaviest parameters whose hashing takes no longer than the given target on
+// the current machine.
+//
+// Starting from 1 MiB of memory and a single iteration, it doubles the memory up to the maximum set
+// by argon2.WithMaxMemory (1 GiB by default), then adds iterations, measuring a hash at each step.
+// The variant, parallelism, key length and salt length are taken from the given options. If even the
+// starting parameters exceed the target, they are returned as the cheapest option available.
+func Calibrate(target time.Duration, opts ...Option) (Params, error) {
+	if target <= 0 {
+		return Params{}, fmt.Errorf("%w: target must be positive", ErrInvalidOption)
+	}
+
+	o, err := newOptions(opts)
+	if err != nil {
+		return Params{}, err
+	}
+
+	p := o.params()
+	p.Iterations = 1
+	p.Memory = calibrationStartMemory
+
+	if floor := minMemoryPerLane * uint32(p.Parallelism); p.Memory < floor {
+		p.Memory = floor
+	}
+
+	if p.Memory > o.maxMemory {
+		p.Memory = o.maxMemory
+	}
+
+	for elapsed := measure(p); elapsed < target; {
+		next := p
+		if next.Memory <= o.maxMemory/2 {
+			next.Memory *= 2
+		} else {
+			next.Iterations++
+		}
+
+		d := measure(next)
+		if d > target {
+			break
+		}
+
+		p, elapsed = next, d
+	}
+
+	return p, nil
+}
+
+// Benchmark returns the time it takes to hash with the given parameters on the current machine.
+//
+// Unlike argon2.Calibrate, it measures a single hash with fixed parameters, which are validated first.
+func Benchmark(params Params) (time.Duration, error) {
+	if _, err := newOptions(params.options()); err != nil {
+		return 0, err
+	}
+
+	return measure(params), nil
+}
+
+// BenchmarkDefault returns the time it takes to hash with the default parameters on the current machine.
+func BenchmarkDefault() (time.Duration, error) {
+	return Benchmark(Defaults())
+}
+
+// Stats describes the resources used to compute a hash.
+type Stats struct {
+	// Duration is the wall time taken to compute the hash.
+	Duration time.Duration
+
+	// MemoryBytes is the memory used by the computation, derived from the memory parameter.
+	MemoryBytes uint64
+}
+
+// HashWithStats returns a new argon2.Argon2 by hashing the given password with the given parameters,
+// along with the resources used to compute it.
+func HashWithStats(password string, params Params) (Argon2, Stats, error) {
+	o, err := newOptions(params.options())
+	if err != nil {
+		return Argon2{}, Stats{}, err
+	}
+
+	start := time.Now()
+
+	a, err := newBytes([]byte(password), o)
+	if err != nil {
+		return Argon2{}, Stats{}, err
+	}
+
+	return a, Stats{Duration: time.Since(start), MemoryBytes: uint64(params.Memory) * 1024}, nil
+}
+
+// measure returns the time it takes to hash with the given parameters.
+func measure(p Params) time.Duration {
+	a := Argon2{
+		variant:     p.Variant,
+		salt:        make([]byte, p.SaltLength),
+		iterations:  p.Iterations,
+		memory:      p.Memory,
+		parallelism: p.Parallelism,
+		keyLength:   p.KeyLength,
+	}
+
+	start := time.Now()
+	a.makeHash([]byte("calibration"))
+
+	return time.Since(start)
+}
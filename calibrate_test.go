@@ -0,0 +1,119 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestCalibrate(t *testing.T) {
+	target := 50 * time.Millisecond
+
+	params, err := argon2.Calibrate(target, argon2.WithParallelism(1), argon2.WithMaxMemory(64*1024))
+	if err != nil {
+		t.Fatalf("failed to calibrate: %s", err)
+	}
+
+	if params.Parallelism != 1 {
+		t.Errorf("expected the parallelism to be kept, got %d", params.Parallelism)
+	}
+
+	if params.Memory > 64*1024 {
+		t.Errorf("expected the memory to stay within the limit, got %d", params.Memory)
+	}
+
+	if params.Iterations < 1 {
+		t.Errorf("expected at least one iteration, got %d", params.Iterations)
+	}
+
+	// The calibrated parameters are measured again in the same run, on the same possibly loaded or
+	// instrumented machine, keeping the fastest of a few runs; the bound only catches gross misses.
+	fastest := time.Duration(math.MaxInt64)
+
+	for i := 0; i < 3; i++ {
+		elapsed, benchErr := argon2.Benchmark(params)
+		if benchErr != nil {
+			t.Fatalf("failed to benchmark: %s", benchErr)
+		}
+
+		if elapsed < fastest {
+			fastest = elapsed
+		}
+	}
+
+	if fastest > 10*target {
+		t.Errorf("expected hashing to take about %s, took %s with %+v", target, fastest, params)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	params := argon2.Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, KeyLength: 32, SaltLength: 16}
+
+	elapsed, err := argon2.Benchmark(params)
+	if err != nil {
+		t.Fatalf("failed to benchmark: %s", err)
+	}
+
+	if elapsed <= 0 {
+		t.Errorf("expected a positive duration, got %s", elapsed)
+	}
+
+	if elapsed, err = argon2.BenchmarkDefault(); err != nil || elapsed <= 0 {
+		t.Errorf("expected a positive duration, got %s, %v", elapsed, err)
+	}
+
+	params.Parallelism = 0
+	if _, err = argon2.Benchmark(params); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestHashWithStats(t *testing.T) {
+	params := argon2.Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, KeyLength: 32, SaltLength: 16}
+
+	a, stats, err := argon2.HashWithStats("password", params)
+	if err != nil {
+		t.Fatalf("failed to hash: %s", err)
+	}
+
+	if stats.MemoryBytes != uint64(params.Memory)*1024 {
+		t.Errorf("expected %d bytes of memory, got %d", uint64(params.Memory)*1024, stats.MemoryBytes)
+	}
+
+	if stats.Duration <= 0 {
+		t.Errorf("expected a positive duration, got %s", stats.Duration)
+	}
+
+	if compareErr := a.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+
+	if _, _, err = argon2.HashWithStats("password", argon2.Params{}); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func BenchmarkHash(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := argon2.New("password"); err != nil {
+			b.Fatalf("failed to create: %s", err)
+		}
+	}
+}
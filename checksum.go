@@ -0,0 +1,50 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned when a checked hash does not match its checksum.
+var ErrChecksumMismatch = errors.New("the encoded hash does not match its checksum")
+
+// EncodeChecked returns an encoded value of the hash followed by "#" and the CRC32 of the encoded value in hex.
+func (a Argon2) EncodeChecked() string {
+	if !a.isValid {
+		return ""
+	}
+
+	encoded := a.Encode()
+
+	return fmt.Sprintf("%s#%08x", encoded, crc32.ChecksumIEEE([]byte(encoded)))
+}
+
+// NewByChecked returns a new argon2.Argon2 by verifying and decoding the given value produced by EncodeChecked.
+func NewByChecked(s string) (Argon2, error) {
+	encoded, sum, ok := strings.Cut(s, "#")
+	if !ok {
+		return Argon2{}, fmt.Errorf("%w: missing checksum", ErrInvalidEncodedHash)
+	}
+
+	if sum != fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(encoded))) {
+		return Argon2{}, ErrChecksumMismatch
+	}
+
+	return NewByEncoded(encoded)
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestArgon2Checked(t *testing.T) {
+	testCases := []string{"password", "secret"}
+
+	for idx, testCase := range testCases {
+		checked := argon2.MustNew(testCase).EncodeChecked()
+
+		a, err := argon2.NewByChecked(checked)
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		if compareErr := a.Compare(testCase); compareErr != nil {
+			t.Errorf("in case %d failed to match", idx)
+		}
+
+		corrupted := []byte(checked)
+		corrupted[len(corrupted)/2] ^= 0x01
+
+		if _, err := argon2.NewByChecked(string(corrupted)); !errors.Is(err, argon2.ErrChecksumMismatch) {
+			t.Errorf("in case %d expected ErrChecksumMismatch, got %v", idx, err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+var defaults = Params{
+	Variant:     VariantID,
+	Memory:      memory,
+	Iterations:  iterations,
+	Parallelism: parallelism,
+	KeyLength:   keyLength,
+	SaltLength:  saltLength,
+}
+
+// Defaults returns the parameters used to create hashes when no options are given.
+func Defaults() Params {
+	return defaults
+}
+
+// SetDefaults replaces the parameters used to create hashes when no options are given.
+//
+// The parameters are validated as if they were passed as options; on error, the defaults are left unchanged.
+// It is meant to be called once at startup, before any hashing takes place, and is not safe for use
+// concurrently with hashing.
+func SetDefaults(params Params) error {
+	o, err := newOptions(params.options())
+	if err != nil {
+		return err
+	}
+
+	if err = o.checkSaltLength(); err != nil {
+		return err
+	}
+
+	defaults = params
+
+	return nil
+}
+
+// options returns the options that configure the parameters.
+func (p Params) options() []Option {
+	return []Option{
+		WithVariant(p.Variant),
+		WithMemory(p.Memory),
+		WithIterations(p.Iterations),
+		WithParallelism(p.Parallelism),
+		WithKeyLength(p.KeyLength),
+		WithSaltLength(p.SaltLength),
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestSetDefaults(t *testing.T) {
+	original := argon2.Defaults()
+	defer func() {
+		if err := argon2.SetDefaults(original); err != nil {
+			t.Fatalf("failed to restore the defaults: %s", err)
+		}
+	}()
+
+	want := argon2.Params{
+		Variant:     argon2.VariantI,
+		Memory:      16 * 1024,
+		Iterations:  4,
+		Parallelism: 1,
+		KeyLength:   16,
+		SaltLength:  8,
+	}
+
+	if err := argon2.SetDefaults(want); err != nil {
+		t.Fatalf("failed to set the defaults: %s", err)
+	}
+
+	if got := argon2.Defaults(); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	a, err := argon2.New("password")
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if a.SaltLength() != int(want.SaltLength) {
+		t.Errorf("expected a salt of %d bytes, got %d", want.SaltLength, a.SaltLength())
+	}
+
+	if !strings.HasPrefix(a.Encode(), "$argon2i$v=19$m=16384,t=4,p=1$") {
+		t.Errorf("unexpected encoding %s", a.Encode())
+	}
+
+	if a.NeedsRehash(want) {
+		t.Error("expected the hash not to need a rehash")
+	}
+
+	if compareErr := a.Compare("password"); compareErr != nil {
+		t.Errorf("failed to compare: %s", compareErr)
+	}
+}
+
+func TestSetDefaultsInvalid(t *testing.T) {
+	original := argon2.Defaults()
+
+	testCases := []argon2.Params{
+		{Memory: 64 * 1024, Iterations: 0, Parallelism: 2, KeyLength: 32, SaltLength: 16},
+		{Memory: 64 * 1024, Iterations: 3, Parallelism: 0, KeyLength: 32, SaltLength: 16},
+		{Memory: 8, Iterations: 3, Parallelism: 2, KeyLength: 32, SaltLength: 16},
+		{Memory: 64 * 1024, Iterations: 3, Parallelism: 2, KeyLength: 2, SaltLength: 16},
+		{Memory: 64 * 1024, Iterations: 3, Parallelism: 2, KeyLength: 32, SaltLength: 0},
+		{Variant: argon2.VariantD, Memory: 64 * 1024, Iterations: 3, Parallelism: 2, KeyLength: 32, SaltLength: 16},
+	}
+
+	for idx, testCase := range testCases {
+		if err := argon2.SetDefaults(testCase); err == nil {
+			t.Errorf("in case %d expected an error", idx)
+		}
+
+		if got := argon2.Defaults(); got != original {
+			t.Errorf("in case %d expected the defaults to be unchanged, got %+v", idx, got)
+		}
+	}
+
+	if err := argon2.SetDefaults(argon2.Params{}); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestSetDefaultsCompareConstant(t *testing.T) {
+	original := argon2.Defaults()
+	defer func() {
+		if err := argon2.SetDefaults(original); err != nil {
+			t.Fatalf("failed to restore the defaults: %s", err)
+		}
+	}()
+
+	params := argon2.Params{
+		Variant:     argon2.VariantID,
+		Memory:      128,
+		Iterations:  5,
+		Parallelism: 1,
+		KeyLength:   24,
+		SaltLength:  16,
+	}
+
+	if err := argon2.SetDefaults(params); err != nil {
+		t.Fatalf("failed to set the defaults: %s", err)
+	}
+
+	k := &recordingKDF{}
+
+	argon2.SetKDF(k)
+	defer argon2.SetKDF(nil)
+
+	if (argon2.Argon2{}).CompareConstant("password") {
+		t.Errorf("expected an invalid hash not to match")
+	}
+
+	want := recordedCall{params.Iterations, params.Memory, params.Parallelism, params.KeyLength}
+	if len(k.calls) != 1 || k.calls[0] != want {
+		t.Errorf("expected a single dummy derivation with %+v, got %+v", want, k.calls)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import "encoding/base64"
+
+// Encoder encodes and decodes the salt and hashed segments of an encoded hash.
+//
+// The default encoder uses standard base64 without padding, as the PHC string format requires.
+// Any other encoder, such as base58, produces encoded hashes that other argon2 implementations
+// cannot read.
+type Encoder interface {
+	Encode(b []byte) string
+	Decode(s string) ([]byte, error)
+}
+
+type base64Encoder struct{}
+
+var _ Encoder = base64Encoder{}
+
+// Encode implements argon2.Encoder.
+func (base64Encoder) Encode(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+// Decode implements argon2.Encoder.
+func (base64Encoder) Decode(s string) ([]byte, error) {
+	return decodeBase64(s)
+}
+
+type base64URLEncoder struct{}
+
+var _ Encoder = base64URLEncoder{}
+
+// Encode implements argon2.Encoder.
+func (base64URLEncoder) Encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode implements argon2.Encoder.
+func (base64URLEncoder) Decode(s string) ([]byte, error) {
+	return decodeBase64(s)
+}
+
+func (a Argon2) encoding() Encoder {
+	if a.encoder == nil {
+		return base64Encoder{}
+	}
+
+	return a.encoder
+}
@@ -0,0 +1,142 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+type hexEncoder struct{}
+
+func (hexEncoder) Encode(b []byte) string {
+	return hex.EncodeToString(b)
+}
+
+func (hexEncoder) Decode(s string) ([]byte, error) {
+	return hex.DecodeString(s)
+}
+
+func TestArgon2Encoder(t *testing.T) {
+	a, err := argon2.New("password", argon2.WithEncoder(hexEncoder{}))
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	encoded := a.Encode()
+	segments := strings.Split(encoded, "$")
+
+	if _, err := hex.DecodeString(segments[len(segments)-1]); err != nil {
+		t.Errorf("expected a hex-encoded hash segment, got %s", encoded)
+	}
+
+	b, err := argon2.NewByEncoded(encoded, argon2.WithEncoder(hexEncoder{}))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if b.Encode() != encoded {
+		t.Errorf("expected %s, got %s", encoded, b)
+	}
+
+	if compareErr := b.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+}
+
+func TestArgon2CanonicalBase64Only(t *testing.T) {
+	testCases := []struct {
+		args    string
+		wantErr bool
+	}{
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			false,
+		},
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OB$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			true,
+		},
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA==$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			true,
+		},
+	}
+
+	for idx, testCase := range testCases {
+		a, err := argon2.NewByEncoded(testCase.args)
+		if err != nil {
+			t.Errorf("in case %d failed to decode without the option: %s", idx, err)
+		} else {
+			if compareErr := a.Compare("password"); compareErr != nil {
+				t.Errorf("in case %d failed to match", idx)
+			}
+		}
+
+		_, err = argon2.NewByEncoded(testCase.args, argon2.WithCanonicalBase64Only())
+		if testCase.wantErr && !errors.Is(err, argon2.ErrNonCanonicalBase64) {
+			t.Errorf("in case %d expected ErrNonCanonicalBase64, got %v", idx, err)
+		}
+
+		if !testCase.wantErr && err != nil {
+			t.Errorf("in case %d error is not expected: %s", idx, err)
+		}
+	}
+}
+
+func TestArgon2URLEncoding(t *testing.T) {
+	std := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+	url := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd-cG4er9wu3DgYCBJb2w3nnI8"
+
+	testCases := []struct {
+		opts []argon2.Option
+		want string
+	}{
+		{nil, std},
+		{[]argon2.Option{argon2.WithURLEncoding()}, url},
+	}
+
+	for idx, testCase := range testCases {
+		for _, args := range []string{std, url} {
+			a, err := argon2.NewByEncoded(args, testCase.opts...)
+			if err != nil {
+				t.Errorf("in case %d failed to decode %s: %s", idx, args, err)
+
+				continue
+			}
+
+			if a.Encode() != testCase.want {
+				t.Errorf("in case %d expected %s, got %s", idx, testCase.want, a)
+			}
+
+			if compareErr := a.Compare("password"); compareErr != nil {
+				t.Errorf("in case %d failed to match %s", idx, args)
+			}
+		}
+	}
+
+	a, err := argon2.New("password", argon2.WithURLEncoding(), argon2.WithSalt([]byte("X9BQMyZQx8SFwwz8")))
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if a.Encode() != url {
+		t.Errorf("expected %s, got %s", url, a)
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"math"
+	"time"
+)
+
+// EstimateCrackTime returns a rough estimate of the time an attacker needs to brute-force the hash.
+//
+// This is a heuristic for reporting, not a guarantee. It assumes that:
+//   - guessesPerSecond is the attacker's rate against a hash with the default parameters
+//     (m=65536, t=3, p=2) of this package;
+//   - the cost of a single guess scales linearly with memory times iterations, and parallelism
+//     does not change the total work;
+//   - the password was chosen uniformly at random from charsetSize symbols with the given length,
+//     and the attacker finds it after searching half of that space on average.
+//
+// Real passwords are rarely uniformly random, so dictionary attacks usually succeed much sooner.
+// The result saturates at the maximum time.Duration.
+func (a Argon2) EstimateCrackTime(guessesPerSecond float64, charsetSize, length int) time.Duration {
+	if !a.isValid || guessesPerSecond <= 0 || charsetSize <= 0 || length <= 0 {
+		return 0
+	}
+
+	cost := (float64(a.memory) * float64(a.iterations)) / (float64(memory) * float64(iterations))
+	space := math.Pow(float64(charsetSize), float64(length))
+	seconds := space / 2 * cost / guessesPerSecond
+
+	if d := seconds * float64(time.Second); d < math.MaxInt64 {
+		return time.Duration(d)
+	}
+
+	return time.Duration(math.MaxInt64)
+}
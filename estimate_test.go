@@ -0,0 +1,59 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestArgon2EstimateCrackTime(t *testing.T) {
+	weak, err := argon2.NewByEncoded(
+		"$argon2id$v=19$m=19456,t=2,p=1$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	strong, err := argon2.NewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	testCases := []struct {
+		charsetSize int
+		length      int
+	}{
+		{26, 6},
+		{62, 6},
+		{36, 8},
+	}
+
+	for idx, testCase := range testCases {
+		w := weak.EstimateCrackTime(1000, testCase.charsetSize, testCase.length)
+		s := strong.EstimateCrackTime(1000, testCase.charsetSize, testCase.length)
+
+		if w <= 0 {
+			t.Errorf("in case %d expected a positive estimate, got %s", idx, w)
+		}
+
+		if s <= w {
+			t.Errorf("in case %d expected stronger parameters to take longer, got %s and %s", idx, w, s)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+// SetNumCPU replaces the function reporting the number of usable CPUs and returns a function restoring it.
+func SetNumCPU(f func() int) func() {
+	prev := numCPU
+	numCPU = f
+
+	return func() {
+		numCPU = prev
+	}
+}
+
+// WithCost returns a copy of the hash with the given cost, bypassing the checks of the decoders.
+func (a Argon2) WithCost(memory, iterations uint32, parallelism uint8) Argon2 {
+	a.memory = memory
+	a.iterations = iterations
+	a.parallelism = parallelism
+
+	return a
+}
+
+// NewDefaultHasher returns a new argon2.Hasher configured the same way as argon2.Default, bypassing its once.
+func NewDefaultHasher() (*Hasher, error) {
+	return newDefaultHasher()
+}
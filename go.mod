@@ -2,6 +2,9 @@ module github.com/merajsahebdar/argon2
 
 go 1.19
 
-require golang.org/x/crypto v0.6.0
+require (
+	golang.org/x/crypto v0.6.0
+	golang.org/x/text v0.7.0
+)
 
 require golang.org/x/sys v0.5.0 // indirect
@@ -0,0 +1,149 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PasswordHasher hashes and verifies passwords.
+//
+// It is implemented by argon2.Hasher. Depending on it rather than on argon2.Hasher allows a fake
+// implementation to be injected in tests, avoiding the cost of computing real hashes.
+type PasswordHasher interface {
+	Hash(password string) (Argon2, error)
+	Verify(encoded, password string) (bool, error)
+}
+
+var _ PasswordHasher = (*Hasher)(nil)
+
+// Hasher hashes and verifies passwords using a fixed set of options.
+//
+// The options are validated once by argon2.NewHasher. A Hasher is safe for concurrent use.
+type Hasher struct {
+	o options
+}
+
+// NewHasher returns a new argon2.Hasher using the given options.
+func NewHasher(opts ...Option) (*Hasher, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = o.checkSaltLength(); err != nil {
+		return nil, err
+	}
+
+	return &Hasher{o: o}, nil
+}
+
+// Hash returns a new argon2.Argon2 by hashing the given password.
+func (h *Hasher) Hash(password string) (Argon2, error) {
+	return newBytes([]byte(password), h.o)
+}
+
+// Verify reports whether the given password matches the given encoded hash.
+//
+// A mismatch is reported as false with no error; an error is returned if the hash cannot be decoded
+// or compared.
+func (h *Hasher) Verify(encoded, password string) (bool, error) {
+	return compareEncoded(encoded, password, h.o)
+}
+
+// The environment variables read by argon2.Default, each overriding the matching parameter of argon2.Defaults.
+const (
+	EnvMemory      = "ARGON2_MEMORY"
+	EnvIterations  = "ARGON2_ITERATIONS"
+	EnvParallelism = "ARGON2_PARALLELISM"
+)
+
+var (
+	defaultHasher     *Hasher
+	defaultHasherOnce sync.Once
+)
+
+// Default returns the process-wide argon2.Hasher, created on first use with the parameters of argon2.Defaults.
+//
+// The memory in KiB, iterations and parallelism can be overridden by the argon2.EnvMemory,
+// argon2.EnvIterations and argon2.EnvParallelism environment variables. It panics if any of them is set to
+// an unacceptable value, rather than silently hashing with parameters other than the configured ones.
+//
+// It is safe for concurrent use, including the first call. Since the parameters and environment are read
+// once, argon2.SetDefaults must be called before the first call to take effect.
+func Default() *Hasher {
+	defaultHasherOnce.Do(func() {
+		h, err := newDefaultHasher()
+		if err != nil {
+			panic(fmt.Errorf("failed to create the default hasher: %w", err))
+		}
+
+		defaultHasher = h
+	})
+
+	return defaultHasher
+}
+
+// newDefaultHasher returns a new argon2.Hasher with the parameters of argon2.Defaults, overridden by the
+// environment.
+func newDefaultHasher() (*Hasher, error) {
+	opts, err := envOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHasher(opts...)
+}
+
+// envOptions returns the options set by the environment variables read by argon2.Default.
+func envOptions() ([]Option, error) {
+	vars := []struct {
+		name    string
+		bitSize int
+		option  func(n uint64) Option
+	}{
+		{EnvMemory, 32, func(n uint64) Option { return WithMemory(uint32(n)) }},
+		{EnvIterations, 32, func(n uint64) Option { return WithIterations(uint32(n)) }},
+		{EnvParallelism, 8, func(n uint64) Option { return WithParallelism(uint8(n)) }},
+	}
+
+	var opts []Option
+
+	for _, v := range vars {
+		val, ok := os.LookupEnv(v.name)
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.ParseUint(strings.TrimSpace(val), 10, v.bitSize)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: %s must be a number of at most %d bits, got %q",
+				ErrInvalidOption,
+				v.name,
+				v.bitSize,
+				val,
+			)
+		}
+
+		opts = append(opts, v.option(n))
+	}
+
+	return opts, nil
+}
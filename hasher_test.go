@@ -0,0 +1,179 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestHasher(t *testing.T) {
+	h, err := argon2.NewHasher(argon2.WithMemory(16*1024), argon2.WithIterations(2), argon2.WithParallelism(1))
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	testCases := []string{"password", "secret", "another"}
+
+	for idx, testCase := range testCases {
+		a, err := h.Hash(testCase)
+		if err != nil {
+			t.Errorf("in case %d failed to hash: %s", idx, err)
+
+			continue
+		}
+
+		if !strings.Contains(a.Encode(), "$m=16384,t=2,p=1$") {
+			t.Errorf("in case %d expected the configured parameters, got %s", idx, a)
+		}
+
+		if ok, err := h.Verify(a.Encode(), testCase); err != nil || !ok {
+			t.Errorf("in case %d failed to verify: %t, %v", idx, ok, err)
+		}
+
+		if ok, err := h.Verify(a.Encode(), "wrong"); err != nil || ok {
+			t.Errorf("in case %d expected a mismatch: %t, %v", idx, ok, err)
+		}
+	}
+
+	if _, err := h.Verify("malformed", "password"); err == nil {
+		t.Errorf("expected an error on a malformed hash")
+	}
+
+	if _, err := argon2.NewHasher(argon2.WithParallelism(0)); err == nil {
+		t.Errorf("expected an error on invalid options")
+	}
+}
+
+func TestDefault(t *testing.T) {
+	original := argon2.Defaults()
+	defer func() {
+		if err := argon2.SetDefaults(original); err != nil {
+			t.Fatalf("failed to restore the defaults: %s", err)
+		}
+	}()
+
+	params := original
+	params.Memory = 64
+	params.Iterations = 1
+	params.Parallelism = 1
+
+	if err := argon2.SetDefaults(params); err != nil {
+		t.Fatalf("failed to set the defaults: %s", err)
+	}
+
+	const n = 16
+
+	var wg sync.WaitGroup
+
+	hashers := make([]*argon2.Hasher, n)
+	hashes := make([]argon2.Argon2, n)
+	errs := make([]error, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			hashers[i] = argon2.Default()
+			hashes[i], errs[i] = hashers[i].Hash(fmt.Sprintf("password%d", i))
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		if hashers[i] == nil || hashers[i] != hashers[0] {
+			t.Errorf("in goroutine %d expected the same hasher, got %p and %p", i, hashers[i], hashers[0])
+		}
+
+		if errs[i] != nil {
+			t.Errorf("in goroutine %d failed to hash: %s", i, errs[i])
+
+			continue
+		}
+
+		if ok, err := argon2.Default().Verify(hashes[i].Encode(), fmt.Sprintf("password%d", i)); err != nil || !ok {
+			t.Errorf("in goroutine %d failed to verify: %t, %v", i, ok, err)
+		}
+	}
+}
+
+func TestDefaultEnv(t *testing.T) {
+	t.Setenv(argon2.EnvMemory, "64")
+	t.Setenv(argon2.EnvIterations, "1")
+	t.Setenv(argon2.EnvParallelism, "1")
+
+	h, err := argon2.NewDefaultHasher()
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	a, err := h.Hash("password")
+	if err != nil {
+		t.Fatalf("failed to hash: %s", err)
+	}
+
+	if want := "$m=64,t=1,p=1$"; !strings.Contains(a.Encode(), want) {
+		t.Errorf("expected the parameters %s, got %s", want, a)
+	}
+
+	for idx, args := range []struct{ name, val string }{
+		{argon2.EnvParallelism, "256"},
+		{argon2.EnvIterations, "three"},
+		{argon2.EnvMemory, "-1"},
+	} {
+		t.Setenv(args.name, args.val)
+
+		if _, err = argon2.NewDefaultHasher(); !errors.Is(err, argon2.ErrInvalidOption) {
+			t.Errorf("in case %d expected error %v, got %v", idx, argon2.ErrInvalidOption, err)
+		}
+
+		t.Setenv(args.name, "1")
+	}
+}
+
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(string) (argon2.Argon2, error) {
+	return argon2.Argon2{}, nil
+}
+
+func (fakeHasher) Verify(encoded, password string) (bool, error) {
+	return encoded == "fake:"+password, nil
+}
+
+func ExamplePasswordHasher() {
+	login := func(h argon2.PasswordHasher, stored, password string) string {
+		ok, err := h.Verify(stored, password)
+		if err != nil || !ok {
+			return "denied"
+		}
+
+		return "granted"
+	}
+
+	fmt.Println(login(fakeHasher{}, "fake:password", "password"))
+	fmt.Println(login(fakeHasher{}, "fake:password", "secret"))
+	// Output:
+	// granted
+	// denied
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	djangoPrefix = "argon2"
+	springPrefix = "{argon2}"
+)
+
+// Limits of libsodium's Argon2 implementation, see crypto_pwhash_argon2id.h and crypto_pwhash_argon2i.h.
+const (
+	libsodiumOpsLimitMin    = 1
+	libsodiumOpsLimitMinI   = 3
+	libsodiumMemLimitMinKiB = 8192 / 1024
+	libsodiumBytesMin       = 16
+	libsodiumSaltBytesMin   = 8
+)
+
+// DjangoString returns the hash in the format used by the Django Argon2 password hasher.
+//
+// The format is the encoded hash prefixed with "argon2", e.g. argon2$argon2id$v=19$m=...,t=...,p=...$salt$hash.
+// The salt and hashed segments are always in standard base64, regardless of the configured encoder.
+// A hash decoded with argon2.WithExternalSalt cannot be exported, since Django requires the salt segment.
+func (a Argon2) DjangoString() (string, error) {
+	if !a.isValid {
+		return "", ErrInvalid
+	}
+
+	if a.externalSalt {
+		return "", fmt.Errorf("%w: django requires the salt in the encoded hash", ErrInvalidSalt)
+	}
+
+	b := a
+	b.encoder = nil
+
+	return djangoPrefix + b.Encode(), nil
+}
+
+// NewByDjango returns a new argon2.Argon2 by decoding the given hash produced by the Django Argon2 password hasher.
+func NewByDjango(s string, opts ...Option) (Argon2, error) {
+	encoded := strings.TrimPrefix(s, djangoPrefix)
+	if len(encoded) == len(s) || !strings.HasPrefix(encoded, "$") {
+		return Argon2{}, fmt.Errorf("%w: missing the %q prefix", ErrInvalidEncodedHash, djangoPrefix)
+	}
+
+	return NewByEncoded(encoded, opts...)
+}
+
+// SpringString returns the hash in the format used by Spring Security's DelegatingPasswordEncoder.
+//
+// The format is the encoded hash prefixed with "{argon2}".
+func (a Argon2) SpringString() string {
+	if !a.isValid {
+		return ""
+	}
+
+	return springPrefix + a.Encode()
+}
+
+// NewBySpring returns a new argon2.Argon2 by decoding the given hash in the format used by
+// Spring Security's DelegatingPasswordEncoder.
+func NewBySpring(s string, opts ...Option) (Argon2, error) {
+	encoded := strings.TrimPrefix(s, springPrefix)
+	if len(encoded) == len(s) {
+		return Argon2{}, fmt.Errorf("%w: missing the %q prefix", ErrInvalidEncodedHash, springPrefix)
+	}
+
+	return NewByEncoded(encoded, opts...)
+}
+
+// LibsodiumCompatible reports whether libsodium's crypto_pwhash_str_verify accepts the hash and,
+// if not, the reason why.
+//
+// libsodium requires at least 1 iteration (3 for argon2i), 8 KiB of memory, a 16-byte key and an 8-byte salt,
+// and only parses the standard m, t and p parameters in standard base64.
+func (a Argon2) LibsodiumCompatible() (bool, string) {
+	switch {
+	case !a.isValid:
+		return false, "the hash is not valid"
+	case a.variant == VariantI && a.iterations < libsodiumOpsLimitMinI:
+		return false, fmt.Sprintf("iterations %d is below the minimum of %d for argon2i", a.iterations, libsodiumOpsLimitMinI)
+	case a.iterations < libsodiumOpsLimitMin:
+		return false, fmt.Sprintf("iterations %d is below the minimum of %d", a.iterations, libsodiumOpsLimitMin)
+	case a.memory < libsodiumMemLimitMinKiB:
+		return false, fmt.Sprintf("memory %d KiB is below the minimum of %d KiB", a.memory, libsodiumMemLimitMinKiB)
+	case a.keyLength < libsodiumBytesMin:
+		return false, fmt.Sprintf("key length %d is below the minimum of %d bytes", a.keyLength, libsodiumBytesMin)
+	case len(a.salt) < libsodiumSaltBytesMin:
+		return false, fmt.Sprintf("salt length %d is below the minimum of %d bytes", len(a.salt), libsodiumSaltBytesMin)
+	case a.budget != 0:
+		return false, "the budget parameter is not supported"
+	case a.keyID != nil:
+		return false, "the keyid parameter is not supported"
+	case a.data != nil:
+		return false, "the data parameter is not supported"
+	}
+
+	if _, ok := a.encoding().(base64Encoder); !ok {
+		return false, "only standard base64 is supported"
+	}
+
+	return true, ""
+}
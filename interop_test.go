@@ -0,0 +1,191 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestArgon2Django(t *testing.T) {
+	testCases := []string{"password", "secret"}
+
+	for idx, testCase := range testCases {
+		s, err := argon2.MustNew(testCase).DjangoString()
+		if err != nil {
+			t.Errorf("in case %d failed to encode: %s", idx, err)
+
+			continue
+		}
+
+		if !strings.HasPrefix(s, "argon2$argon2id$") {
+			t.Errorf("in case %d expected the django prefix, got %s", idx, s)
+		}
+
+		a, err := argon2.NewByDjango(s)
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+		} else {
+			if compareErr := a.Compare(testCase); compareErr != nil {
+				t.Errorf("in case %d failed to match", idx)
+			}
+		}
+	}
+
+	if _, err := (argon2.Argon2{}).DjangoString(); err == nil {
+		t.Errorf("expected an error for an invalid hash")
+	}
+}
+
+func TestArgon2DjangoSample(t *testing.T) {
+	// Produced by Django's Argon2PasswordHasher for "secret", from the test suite of Django.
+	testCases := []string{
+		"argon2$argon2i$v=19$m=8,t=1,p=1$c2FsdHNhbHQ$YC9+jJCrQhs5R6db7LlN8Q",
+		"argon2$argon2id$v=19$m=102400,t=2,p=8$Y041dExhNkljRUUy$TMa6A8fPJhCAUXRhJXCXdw",
+	}
+
+	for idx, testCase := range testCases {
+		a, err := argon2.NewByDjango(testCase)
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		if compareErr := a.Compare("secret"); compareErr != nil {
+			t.Errorf("in case %d failed to match: %s", idx, compareErr)
+		}
+
+		if compareErr := a.Compare("wrong"); !errors.Is(compareErr, argon2.ErrMismatched) {
+			t.Errorf("in case %d expected ErrMismatched, got %v", idx, compareErr)
+		}
+
+		if s, encodeErr := a.DjangoString(); encodeErr != nil || s != testCase {
+			t.Errorf("in case %d expected %s, got %s, %v", idx, testCase, s, encodeErr)
+		}
+
+		if _, err = argon2.NewByDjango(strings.TrimPrefix(testCase, "argon2")); err == nil {
+			t.Errorf("in case %d expected an error without the django prefix", idx)
+		}
+	}
+}
+
+func TestArgon2DjangoExternalSalt(t *testing.T) {
+	a, err := argon2.NewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+		argon2.WithExternalSalt([]byte("X9BQMyZQx8SFwwz8")),
+	)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if _, err = a.DjangoString(); !errors.Is(err, argon2.ErrInvalidSalt) {
+		t.Errorf("expected error %v, got %v", argon2.ErrInvalidSalt, err)
+	}
+}
+
+func TestArgon2LibsodiumCompatible(t *testing.T) {
+	testCases := []struct {
+		args string
+		want bool
+	}{
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			true,
+		},
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoG",
+			false,
+		},
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			false,
+		},
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2,data=YXNzb2NpYXRlZA" +
+				"$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			false,
+		},
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2,keyid=a2V5MQ" +
+				"$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			false,
+		},
+	}
+
+	for idx, testCase := range testCases {
+		a, err := argon2.NewByEncoded(
+			testCase.args,
+			argon2.WithDigestLengthRange(4, 64),
+			argon2.WithMinSaltLength(4),
+		)
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		got, reason := a.LibsodiumCompatible()
+		if got != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, got)
+		}
+
+		if !got && reason == "" {
+			t.Errorf("in case %d expected a reason", idx)
+		}
+	}
+}
+
+func TestArgon2Spring(t *testing.T) {
+	testCases := []string{"password", "secret"}
+
+	for idx, testCase := range testCases {
+		s := argon2.MustNew(testCase).SpringString()
+		if !strings.HasPrefix(s, "{argon2}$argon2id$") {
+			t.Errorf("in case %d expected the spring prefix, got %s", idx, s)
+		}
+
+		a, err := argon2.NewBySpring(s)
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+		} else {
+			if compareErr := a.Compare(testCase); compareErr != nil {
+				t.Errorf("in case %d failed to match", idx)
+			}
+		}
+	}
+}
+
+func TestArgon2SpringSample(t *testing.T) {
+	// The layout of Spring Security's Argon2PasswordEncoder with its default parameters.
+	sample := "{argon2}$argon2id$v=19$m=16384,t=2,p=1$c3ByaW5nLXNhbHQtMDAwMQ" +
+		"$u42qGAwBfXA0kMmiNDoC85Ar569vJdoW/hMmub2xPn0"
+
+	a, err := argon2.NewBySpring(sample)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if compareErr := a.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+
+	if _, err := argon2.NewBySpring(strings.TrimPrefix(sample, "{argon2}")); err == nil {
+		t.Errorf("expected an error without the spring prefix")
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+type jsonbParams struct {
+	Memory      uint32 `json:"m"`
+	Iterations  uint32 `json:"t"`
+	Parallelism uint8  `json:"p"`
+	Budget      uint32 `json:"budget,omitempty"`
+	KeyID       string `json:"keyid,omitempty"`
+	Data        string `json:"data,omitempty"`
+}
+
+type jsonbCredential struct {
+	Variant string      `json:"variant"`
+	Version int         `json:"version"`
+	Params  jsonbParams `json:"params"`
+	Salt    string      `json:"salt"`
+	Digest  string      `json:"digest"`
+}
+
+// JSONBValue returns the hash as a JSON object suitable for a jsonb column.
+//
+// The salt, digest, and key id and associated data if any, are encoded in base64 without padding, the same as in the
+// encoded hash.
+func (a Argon2) JSONBValue() (driver.Value, error) {
+	if !a.isValid {
+		return nil, nil
+	}
+
+	var keyID, data string
+	if a.keyID != nil {
+		keyID = base64.RawStdEncoding.EncodeToString(a.keyID)
+	}
+
+	if a.data != nil {
+		data = base64.RawStdEncoding.EncodeToString(a.data)
+	}
+
+	b, err := json.Marshal(jsonbCredential{
+		Variant: a.variant.String(),
+		Version: a.version,
+		Params: jsonbParams{
+			Memory:      a.memory,
+			Iterations:  a.iterations,
+			Parallelism: a.parallelism,
+			Budget:      a.budget,
+			KeyID:       keyID,
+			Data:        data,
+		},
+		Salt:   base64.RawStdEncoding.EncodeToString(a.salt),
+		Digest: base64.RawStdEncoding.EncodeToString(a.hashed),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode as json: %w", err)
+	}
+
+	return b, nil
+}
+
+func isJSONObject(b []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(b), []byte("{"))
+}
+
+// newByJSONB decodes a hash stored as a JSON object.
+//
+// The fields are checked the same way as the segments of an encoded hash by argon2.NewByEncoded with the
+// default options, from the bounds of the parameters to the lengths of the salt and digest.
+func newByJSONB(b []byte) (Argon2, error) {
+	var c jsonbCredential
+	if err := json.Unmarshal(b, &c); err != nil {
+		return Argon2{}, fmt.Errorf("%w: %s", ErrInvalidEncodedHash, err)
+	}
+
+	o, err := newOptions(nil)
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", c.Params.Memory, c.Params.Iterations, c.Params.Parallelism)
+	if c.Params.Budget != 0 {
+		params += fmt.Sprintf(",budget=%d", c.Params.Budget)
+	}
+
+	if c.Params.KeyID != "" {
+		params += ",keyid=" + c.Params.KeyID
+	}
+
+	if c.Params.Data != "" {
+		params += ",data=" + c.Params.Data
+	}
+
+	return decodeSegments([encodedSlicesCount]string{
+		"",
+		c.Variant,
+		fmt.Sprintf("v=%d", c.Version),
+		params,
+		c.Salt,
+		c.Digest,
+	}, o)
+}
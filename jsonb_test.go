@@ -0,0 +1,129 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestArgon2JSONBValue(t *testing.T) {
+	testCases := []struct {
+		deps argon2.Argon2
+		want string
+	}{
+		{argon2.MustNew("password"), "password"},
+		{argon2.MustNew("secret"), "secret"},
+	}
+
+	for idx, testCase := range testCases {
+		v, err := testCase.deps.JSONBValue()
+		if err != nil {
+			t.Errorf("in case %d error is not expected: %s", idx, err)
+
+			continue
+		}
+
+		b, ok := v.([]byte)
+		if !ok {
+			t.Errorf("in case %d expected a byte slice, got %T", idx, v)
+
+			continue
+		}
+
+		a := &argon2.Argon2{}
+
+		if err := a.Scan(b); err != nil {
+			t.Errorf("in case %d failed to scan: %s", idx, err)
+		} else {
+			if compareErr := a.Compare(testCase.want); compareErr != nil {
+				t.Errorf("in case %d failed to match", idx)
+			}
+		}
+	}
+
+	if v, err := (argon2.Argon2{}).JSONBValue(); err != nil || v != nil {
+		t.Errorf("expected a nil value for an invalid hash, got %v, %v", v, err)
+	}
+}
+
+func TestArgon2JSONBValueKeyIDAndData(t *testing.T) {
+	a := mustNewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2,keyid=a2V5MQ,data=YXNzb2NpYXRlZA" +
+			"$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+
+	v, err := a.JSONBValue()
+	if err != nil {
+		t.Fatalf("error is not expected: %s", err)
+	}
+
+	b := &argon2.Argon2{}
+	if err = b.Scan(v); err != nil {
+		t.Fatalf("failed to scan: %s", err)
+	}
+
+	if b.Encode() != a.Encode() {
+		t.Errorf("expected %s, got %s", a, b)
+	}
+
+	if compareErr := b.Compare("password"); !errors.Is(compareErr, argon2.ErrAssociatedData) {
+		t.Errorf("expected ErrAssociatedData, got %v", compareErr)
+	}
+}
+
+func TestArgon2JSONBScanBounds(t *testing.T) {
+	valid := `{"variant":"argon2id","version":19,"params":{"m":65536,"t":3,"p":2},` +
+		`"salt":"WDlCUU15WlF4OFNGd3d6OA","digest":"0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"}`
+
+	testCases := []struct {
+		old     string
+		new     string
+		wantErr error
+	}{
+		{`"m":65536,"t":3,"p":2`, `"m":4000000000,"t":1000000,"p":255`, argon2.ErrCostExceeded},
+		{`"m":65536`, `"m":4`, argon2.ErrInvalidParams},
+		{`"t":3`, `"t":0`, argon2.ErrInvalidParams},
+		{"WDlCUU15WlF4OFNGd3d6OA", "AAAA", argon2.ErrInvalidSalt},
+		{"0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8", "AA", argon2.ErrInvalidDigest},
+		{`"version":19`, `"version":20`, argon2.ErrIncompatibleVersion},
+	}
+
+	a := &argon2.Argon2{}
+	if err := a.Scan([]byte(valid)); err != nil {
+		t.Fatalf("failed to scan: %s", err)
+	}
+
+	for idx, testCase := range testCases {
+		a := &argon2.Argon2{}
+
+		err := a.Scan([]byte(strings.Replace(valid, testCase.old, testCase.new, 1)))
+		if !errors.Is(err, testCase.wantErr) {
+			t.Errorf("in case %d expected %v, got %v", idx, testCase.wantErr, err)
+		}
+
+		var decodeErr *argon2.DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Errorf("in case %d expected a DecodeError, got %v", idx, err)
+		}
+
+		if a.Valid() {
+			t.Errorf("in case %d expected an invalid hash", idx)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import "golang.org/x/crypto/argon2"
+
+// KDF derives Argon2id keys.
+//
+// IDKey has the same signature as the one in golang.org/x/crypto/argon2, which is the default
+// implementation. An alternative implementation, e.g. one backed by SIMD or cgo, must produce
+// identical output for identical input. Argon2i keys are always derived by golang.org/x/crypto/argon2.
+type KDF interface {
+	IDKey(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte
+}
+
+type xcryptoKDF struct{}
+
+var _ KDF = xcryptoKDF{}
+
+// IDKey implements argon2.KDF.
+func (xcryptoKDF) IDKey(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	return argon2.IDKey(password, salt, time, memory, threads, keyLen)
+}
+
+var kdf KDF = xcryptoKDF{}
+
+// SetKDF replaces the implementation used to derive keys, or restores the default one if k is nil.
+//
+// It is meant to be called once at startup, before any hashing takes place.
+func SetKDF(k KDF) {
+	if k == nil {
+		k = xcryptoKDF{}
+	}
+
+	kdf = k
+}
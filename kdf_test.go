@@ -0,0 +1,178 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/merajsahebdar/argon2"
+	xargon2 "golang.org/x/crypto/argon2"
+)
+
+type recordingKDF struct {
+	calls []recordedCall
+}
+
+type recordedCall struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	keyLen  uint32
+}
+
+func (k *recordingKDF) IDKey(_, _ []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	k.calls = append(k.calls, recordedCall{time, memory, threads, keyLen})
+
+	return make([]byte, keyLen)
+}
+
+type truncatingKDF struct {
+	calls int
+}
+
+func (k *truncatingKDF) IDKey(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	k.calls++
+
+	return xargon2.IDKey(password, salt, time, memory, threads, keyLen)[:keyLen-1]
+}
+
+// overlapKDF blocks each derivation until it is released, reporting its arrival first, so a test can
+// observe whether derivations run at the same time.
+type overlapKDF struct {
+	arrived chan struct{}
+	release chan struct{}
+}
+
+func (k *overlapKDF) IDKey(_, _ []byte, _, _ uint32, _ uint8, keyLen uint32) []byte {
+	k.arrived <- struct{}{}
+	<-k.release
+
+	return make([]byte, keyLen)
+}
+
+func TestVerifyConcurrent(t *testing.T) {
+	const n = 4
+
+	// The highest cost accepted by default: 1 GiB of memory, which is never allocated by the fake KDF.
+	encoded := "$argon2id$v=19$m=1048576,t=1,p=4$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	h, err := argon2.NewHasher()
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	k := &overlapKDF{arrived: make(chan struct{}, n), release: make(chan struct{})}
+
+	argon2.SetKDF(k)
+	defer argon2.SetKDF(nil)
+
+	var wg sync.WaitGroup
+
+	errs := make([]error, n)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			if i%2 == 0 {
+				_, errs[i] = argon2.Verify(encoded, "password")
+			} else {
+				_, errs[i] = h.Verify(encoded, "password")
+			}
+		}(i)
+	}
+
+	arrived := 0
+	timeout := time.After(10 * time.Second)
+
+wait:
+	for ; arrived < n; arrived++ {
+		select {
+		case <-k.arrived:
+		case <-timeout:
+			break wait
+		}
+	}
+
+	close(k.release)
+	wg.Wait()
+
+	if arrived != n {
+		t.Errorf("expected %d verifies to derive at the same time, got %d", n, arrived)
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("in goroutine %d failed to verify: %s", i, err)
+		}
+	}
+}
+
+// countingKDF derives keys with golang.org/x/crypto/argon2, counting the derivations.
+type countingKDF struct {
+	calls int
+}
+
+func (k *countingKDF) IDKey(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+	k.calls++
+
+	return xargon2.IDKey(password, salt, time, memory, threads, keyLen)
+}
+
+func TestSetKDF(t *testing.T) {
+	k := &recordingKDF{}
+
+	argon2.SetKDF(k)
+	defer argon2.SetKDF(nil)
+
+	if _, err := argon2.New("password"); err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if len(k.calls) != 1 {
+		t.Fatalf("expected a single call, got %d", len(k.calls))
+	}
+
+	if want := (recordedCall{3, 64 * 1024, 2, 32}); k.calls[0] != want {
+		t.Errorf("expected %+v, got %+v", want, k.calls[0])
+	}
+}
+
+func TestArgon2CompareKeyLengthMismatch(t *testing.T) {
+	a, err := argon2.NewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	k := &truncatingKDF{}
+
+	argon2.SetKDF(k)
+	defer argon2.SetKDF(nil)
+
+	if compareErr := a.Compare("password"); !errors.Is(compareErr, argon2.ErrMismatched) {
+		t.Errorf("expected %s, got %v", argon2.ErrMismatched, compareErr)
+	}
+
+	if k.calls != 1 {
+		t.Errorf("expected the key to be derived once, got %d", k.calls)
+	}
+}
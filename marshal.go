@@ -0,0 +1,247 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+var (
+	_ json.Marshaler           = Argon2{}
+	_ json.Unmarshaler         = (*Argon2)(nil)
+	_ encoding.TextMarshaler   = Argon2{}
+	_ encoding.TextUnmarshaler = (*Argon2)(nil)
+	_ gob.GobEncoder           = Argon2{}
+	_ gob.GobDecoder           = (*Argon2)(nil)
+
+	_ encoding.BinaryMarshaler   = Argon2{}
+	_ encoding.BinaryUnmarshaler = (*Argon2)(nil)
+)
+
+// binaryHeaderLength is the length of the fixed header of the binary format: the variant, version,
+// memory, iterations, parallelism, salt length, key length and memory budget.
+const binaryHeaderLength = 17
+
+// MarshalJSON implements json.Marshaler.
+func (a Argon2) MarshalJSON() ([]byte, error) {
+	if !a.isValid {
+		return []byte("null"), nil
+	}
+
+	b, err := json.Marshal(a.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+
+	return b, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (a *Argon2) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*a = Argon2{}
+
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	decoded, err := NewByEncoded(s)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal due to decode error: %w", err)
+	}
+
+	*a = decoded
+
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (a Argon2) MarshalText() ([]byte, error) {
+	if !a.isValid {
+		return []byte{}, nil
+	}
+
+	return []byte(a.Encode()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+//
+// An empty text results in an invalid hash, the same as MarshalText produces for one.
+func (a *Argon2) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*a = Argon2{}
+
+		return nil
+	}
+
+	decoded, err := NewByEncoded(string(text))
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal due to decode error: %w", err)
+	}
+
+	*a = decoded
+
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder.
+//
+// The hash is encoded as its PHC string, or as an empty marker if it is invalid.
+func (a Argon2) GobEncode() ([]byte, error) {
+	return a.MarshalText()
+}
+
+// GobDecode implements gob.GobDecoder.
+func (a *Argon2) GobDecode(b []byte) error {
+	return a.UnmarshalText(b)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// The hash is packed into a 17-byte header holding the variant, version, memory, iterations, parallelism,
+// salt length, key length and memory budget, followed by the raw salt and hashed value. An invalid hash
+// is marshaled as an empty slice. Hashes with a key id, associated data or a salt or hashed value longer than
+// 255 bytes cannot be marshaled.
+func (a Argon2) MarshalBinary() ([]byte, error) {
+	if !a.isValid {
+		return []byte{}, nil
+	}
+
+	if a.data != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", ErrAssociatedData)
+	}
+
+	if a.keyID != nil {
+		return nil, fmt.Errorf("failed to marshal: %w: the key id cannot be packed", ErrInvalidParams)
+	}
+
+	if len(a.salt) > math.MaxUint8 {
+		return nil, fmt.Errorf("failed to marshal: %w: the salt is %d bytes long", ErrInvalidSalt, len(a.salt))
+	}
+
+	if len(a.hashed) > math.MaxUint8 {
+		return nil, fmt.Errorf("failed to marshal: %w: the hashed value is %d bytes long", ErrInvalidDigest, len(a.hashed))
+	}
+
+	b := make([]byte, binaryHeaderLength, binaryHeaderLength+len(a.salt)+len(a.hashed))
+	b[0] = byte(a.variant)
+	b[1] = byte(a.version)
+	binary.BigEndian.PutUint32(b[2:], a.memory)
+	binary.BigEndian.PutUint32(b[6:], a.iterations)
+	b[10] = a.parallelism
+	b[11] = byte(len(a.salt))
+	b[12] = byte(len(a.hashed))
+	binary.BigEndian.PutUint32(b[13:], a.budget)
+
+	b = append(b, a.salt...)
+	b = append(b, a.hashed...)
+
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// The hash is checked the same way as by argon2.NewByEncoded with the default options. An empty slice
+// results in an invalid hash, the same as MarshalBinary produces for one.
+func (a *Argon2) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 {
+		*a = Argon2{}
+
+		return nil
+	}
+
+	decoded, err := unmarshalBinary(b)
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal due to decode error: %w", err)
+	}
+
+	*a = decoded
+
+	return nil
+}
+
+func unmarshalBinary(b []byte) (Argon2, error) {
+	if len(b) < binaryHeaderLength || len(b) != binaryHeaderLength+int(b[11])+int(b[12]) {
+		return Argon2{}, fmt.Errorf("%w: the binary hash is truncated or has trailing bytes", ErrInvalidEncodedHash)
+	}
+
+	o, err := newOptions(nil)
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	a := Argon2{
+		variant:     Variant(b[0]),
+		version:     int(b[1]),
+		memory:      binary.BigEndian.Uint32(b[2:]),
+		iterations:  binary.BigEndian.Uint32(b[6:]),
+		parallelism: b[10],
+		budget:      binary.BigEndian.Uint32(b[13:]),
+		salt:        cloneBytes(b[binaryHeaderLength : binaryHeaderLength+int(b[11])]),
+		hashed:      cloneBytes(b[binaryHeaderLength+int(b[11]):]),
+		encoder:     o.encoder,
+		isValid:     true,
+		maxCost:     o.maxCost,
+
+		uniformTiming: o.uniformTiming,
+	}
+	a.keyLength = uint32(len(a.hashed))
+
+	if err = a.variant.supported(); err != nil {
+		return Argon2{}, err
+	}
+
+	if !isCompatibleVersion(a.version, nil) {
+		return Argon2{}, ErrIncompatibleVersion
+	}
+
+	if len(a.salt) < int(o.minSaltLength) {
+		return Argon2{}, fmt.Errorf(
+			"%w: the salt is %d bytes long, expected at least %d",
+			ErrInvalidSalt,
+			len(a.salt),
+			o.minSaltLength,
+		)
+	}
+
+	if n := len(a.hashed); n < int(o.minDigestLength) || n > int(o.maxDigestLength) {
+		return Argon2{}, fmt.Errorf(
+			"%w: the hashed value is %d bytes long, expected between %d and %d; it may have been truncated",
+			ErrInvalidDigest,
+			n,
+			o.minDigestLength,
+			o.maxDigestLength,
+		)
+	}
+
+	if err = a.checkBounds(); err != nil {
+		return Argon2{}, fmt.Errorf("%w: %s", ErrInvalidParams, err)
+	}
+
+	if err = a.checkCost(o.maxCost); err != nil {
+		return Argon2{}, err
+	}
+
+	return a, nil
+}
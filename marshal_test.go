@@ -0,0 +1,245 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestArgon2JSON(t *testing.T) {
+	type user struct {
+		Password argon2.Argon2 `json:"password"`
+	}
+
+	testCases := []struct {
+		deps    argon2.Argon2
+		want    string
+		wantNil bool
+	}{
+		{argon2.MustNew("password"), "password", false},
+		{argon2.MustNew("secret"), "secret", false},
+		{argon2.Argon2{}, "", true},
+	}
+
+	for idx, testCase := range testCases {
+		b, err := json.Marshal(user{Password: testCase.deps})
+		if err != nil {
+			t.Errorf("in case %d failed to marshal: %s", idx, err)
+
+			continue
+		}
+
+		if testCase.wantNil && string(b) != `{"password":null}` {
+			t.Errorf("in case %d expected null, got %s", idx, b)
+		}
+
+		var u user
+		if err := json.Unmarshal(b, &u); err != nil {
+			t.Errorf("in case %d failed to unmarshal: %s", idx, err)
+
+			continue
+		}
+
+		if testCase.wantNil {
+			if v, _ := u.Password.Value(); v != nil {
+				t.Errorf("in case %d expected an invalid hash", idx)
+			}
+
+			continue
+		}
+
+		if compareErr := u.Password.Compare(testCase.want); compareErr != nil {
+			t.Errorf("in case %d failed to match", idx)
+		}
+	}
+
+	var u user
+	if err := json.Unmarshal([]byte(`{"password":"malformed"}`), &u); err == nil {
+		t.Errorf("expected an error on a malformed hash")
+	}
+}
+
+func TestArgon2Text(t *testing.T) {
+	testCases := []struct {
+		deps argon2.Argon2
+		want string
+	}{
+		{argon2.MustNew("password"), "password"},
+		{argon2.MustNew("secret"), "secret"},
+	}
+
+	for idx, testCase := range testCases {
+		text, err := testCase.deps.MarshalText()
+		if err != nil {
+			t.Errorf("in case %d failed to marshal: %s", idx, err)
+
+			continue
+		}
+
+		a := &argon2.Argon2{}
+
+		if err := a.UnmarshalText(text); err != nil {
+			t.Errorf("in case %d failed to unmarshal: %s", idx, err)
+		} else {
+			if compareErr := a.Compare(testCase.want); compareErr != nil {
+				t.Errorf("in case %d failed to match", idx)
+			}
+		}
+	}
+
+	if text, err := (argon2.Argon2{}).MarshalText(); err != nil || len(text) != 0 {
+		t.Errorf("expected an empty text for an invalid hash, got %q, %v", text, err)
+	}
+}
+
+func TestArgon2TextThroughXML(t *testing.T) {
+	type user struct {
+		Password argon2.Argon2 `xml:"password,attr"`
+	}
+
+	b, err := xml.Marshal(user{Password: argon2.MustNew("password")})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+
+	var u user
+	if err := xml.Unmarshal(b, &u); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+
+	if compareErr := u.Password.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+}
+
+func TestArgon2Gob(t *testing.T) {
+	type entry struct {
+		Password argon2.Argon2
+	}
+
+	testCases := []struct {
+		deps      argon2.Argon2
+		want      string
+		wantValid bool
+	}{
+		{argon2.MustNew("password"), "password", true},
+		{argon2.MustNew("secret"), "secret", true},
+		{argon2.Argon2{}, "", false},
+	}
+
+	for idx, testCase := range testCases {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(entry{Password: testCase.deps}); err != nil {
+			t.Errorf("in case %d failed to encode: %s", idx, err)
+
+			continue
+		}
+
+		var e entry
+		if err := gob.NewDecoder(&buf).Decode(&e); err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		if !testCase.wantValid {
+			if v, _ := e.Password.Value(); v != nil {
+				t.Errorf("in case %d expected an invalid hash", idx)
+			}
+
+			continue
+		}
+
+		if compareErr := e.Password.Compare(testCase.want); compareErr != nil {
+			t.Errorf("in case %d failed to match", idx)
+		}
+	}
+}
+
+func TestArgon2Binary(t *testing.T) {
+	testCases := []struct {
+		deps argon2.Argon2
+		want string
+	}{
+		{mustNewByEncoded(
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+		), "password"},
+		{mustNewByEncoded(
+			"$argon2i$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$LemjSGlZG4wIF14JADA5jkdoISphpCdrnpBJdv+BEOM",
+		), "password"},
+		{argon2.MustNew("secret", argon2.WithMaxVerifyMemory(128*1024)), "secret"},
+	}
+
+	for idx, testCase := range testCases {
+		b, err := testCase.deps.MarshalBinary()
+		if err != nil {
+			t.Errorf("in case %d failed to marshal: %s", idx, err)
+
+			continue
+		}
+
+		if len(b) >= len(testCase.deps.Encode()) {
+			t.Errorf("in case %d expected fewer than %d bytes, got %d", idx, len(testCase.deps.Encode()), len(b))
+		}
+
+		a := &argon2.Argon2{}
+		if err = a.UnmarshalBinary(b); err != nil {
+			t.Errorf("in case %d failed to unmarshal: %s", idx, err)
+
+			continue
+		}
+
+		if !a.Equal(testCase.deps) || a.Encode() != testCase.deps.Encode() {
+			t.Errorf("in case %d expected %s, got %s", idx, testCase.deps.Encode(), a.Encode())
+		}
+
+		if compareErr := a.Compare(testCase.want); compareErr != nil {
+			t.Errorf("in case %d failed to match", idx)
+		}
+	}
+
+	if b, err := (argon2.Argon2{}).MarshalBinary(); err != nil || len(b) != 0 {
+		t.Errorf("expected an empty slice for an invalid hash, got %v, %v", b, err)
+	}
+}
+
+func TestArgon2BinaryTruncated(t *testing.T) {
+	b, err := argon2.MustNew("password").MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+
+	for n := 1; n < len(b); n++ {
+		a := &argon2.Argon2{}
+		if err = a.UnmarshalBinary(b[:n]); !errors.Is(err, argon2.ErrInvalidEncodedHash) {
+			t.Errorf("expected ErrInvalidEncodedHash for %d of %d bytes, got %v", n, len(b), err)
+		}
+
+		if a.Valid() {
+			t.Errorf("expected an invalid hash for %d of %d bytes", n, len(b))
+		}
+	}
+
+	if err = (&argon2.Argon2{}).UnmarshalBinary(append(b, 0)); !errors.Is(err, argon2.ErrInvalidEncodedHash) {
+		t.Errorf("expected ErrInvalidEncodedHash for trailing bytes, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import "fmt"
+
+// NormalizeAll decodes the given encoded hashes and returns them in their canonical form.
+//
+// Hashes that fail to decode are returned unchanged, with the error at the same index.
+// Padded base64, a differently cased variant, parameters in a different order and spacing
+// around them are all normalized away.
+func NormalizeAll(encoded []string) ([]string, []error) {
+	normalized := make([]string, len(encoded))
+	errs := make([]error, len(encoded))
+
+	for idx, e := range encoded {
+		a, err := NewByEncoded(e)
+		if err != nil {
+			normalized[idx] = e
+			errs[idx] = err
+
+			continue
+		}
+
+		normalized[idx] = a.Encode()
+	}
+
+	return normalized, errs
+}
+
+// Dedup returns the unique encoded hashes in their canonical form, preserving the order they were first seen.
+//
+// Hashes that differ only in base64 padding or parameter spacing collapse into one.
+func Dedup(encoded []string) ([]string, error) {
+	var unique []string
+
+	seen := make(map[string]struct{}, len(encoded))
+
+	for idx, e := range encoded {
+		a, err := NewByEncoded(e)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode hash %d: %w", idx, err)
+		}
+
+		canonical := a.Encode()
+		if _, ok := seen[canonical]; ok {
+			continue
+		}
+
+		seen[canonical] = struct{}{}
+		unique = append(unique, canonical)
+	}
+
+	return unique, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestDedup(t *testing.T) {
+	encoded := []string{
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+		"$argon2id$v=19$m=65536, t=3, p=2$WDlCUU15WlF4OFNGd3d6OA==$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8=",
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$parPWxJrAJEdk57bpMuCC/kLhKJV4EnMb8205SNrFUQ",
+	}
+
+	unique, err := argon2.Dedup(encoded)
+	if err != nil {
+		t.Fatalf("failed to dedup: %s", err)
+	}
+
+	if len(unique) != 2 {
+		t.Fatalf("expected 2 unique hashes, got %d", len(unique))
+	}
+
+	if unique[0] != encoded[0] || unique[1] != encoded[2] {
+		t.Errorf("expected first-seen order to be preserved, got %v", unique)
+	}
+
+	if _, err := argon2.Dedup([]string{encoded[0], "malformed"}); err == nil {
+		t.Errorf("expected an error on an undecodable entry")
+	}
+}
+
+func TestNormalizeAll(t *testing.T) {
+	canonical := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	encoded := []string{
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA==$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8=",
+		"$ARGON2ID$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+		"$argon2id$v=19$p=2,m=65536,t=3$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+		"malformed",
+	}
+
+	normalized, errs := argon2.NormalizeAll(encoded)
+
+	for idx := range encoded[:3] {
+		if errs[idx] != nil {
+			t.Errorf("in case %d failed to normalize: %s", idx, errs[idx])
+
+			continue
+		}
+
+		if normalized[idx] != canonical {
+			t.Errorf("in case %d expected %s, got %s", idx, canonical, normalized[idx])
+		}
+
+		a, err := argon2.NewByEncoded(normalized[idx])
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+		} else {
+			if compareErr := a.Compare("password"); compareErr != nil {
+				t.Errorf("in case %d failed to match", idx)
+			}
+		}
+	}
+
+	if errs[3] == nil || normalized[3] != encoded[3] {
+		t.Errorf("expected the malformed entry to be kept with an error")
+	}
+}
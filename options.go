@@ -0,0 +1,783 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Option configures how an argon2.Argon2 is created or decoded.
+type Option func(*options) error
+
+// Unit is a unit of memory, expressed in KiB.
+type Unit uint32
+
+const (
+	// KiB is a kibibyte, the unit of the memory parameter in the standard encoding.
+	KiB Unit = 1
+
+	// MiB is a mebibyte.
+	MiB Unit = 1024
+)
+
+// numCPU reports the number of usable CPUs; it is replaced in tests.
+var numCPU = runtime.NumCPU
+
+type options struct {
+	variant     Variant
+	iterations  uint32
+	memory      uint32
+	parallelism uint8
+	keyLength   uint32
+	saltLength  uint32
+	maxMemory   uint32
+	budget      uint32
+	memoryUnit  Unit
+	encoder     Encoder
+
+	canonicalOnly bool
+	minEntropy    float64
+	externalSalt  []byte
+	salt          []byte
+	rand          io.Reader
+	wipeInput     bool
+	pepper        []byte
+
+	allowedVersions []int
+	maxReadSize     int64
+	minDigestLength uint32
+	maxDigestLength uint32
+	minSaltLength   uint32
+	maxCost         Params
+	anyVersion      bool
+	rejectEmpty     bool
+	minLength       int
+	maxLength       int
+	normalize       bool
+	form            norm.Form
+	uniformTiming   time.Duration
+	fallbackPeppers [][]byte
+	pepperRing      PepperRing
+	pepperMap       map[string][]byte
+	keyID           []byte
+}
+
+func newOptions(opts []Option) (options, error) {
+	o := options{
+		variant:     defaults.Variant,
+		iterations:  defaults.Iterations,
+		memory:      defaults.Memory,
+		parallelism: defaults.Parallelism,
+		keyLength:   defaults.KeyLength,
+		saltLength:  defaults.SaltLength,
+		maxMemory:   maxMemory,
+		memoryUnit:  KiB,
+		encoder:     base64Encoder{},
+		rand:        rand.Reader,
+
+		maxReadSize:     defaultMaxReadSize,
+		minDigestLength: minDigestLength,
+		maxDigestLength: maxDigestLength,
+		minSaltLength:   minSaltLength,
+		maxLength:       defaultMaxLength,
+		maxCost:         defaultMaxCost,
+	}
+
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return options{}, err
+		}
+	}
+
+	if err := o.validate(); err != nil {
+		return options{}, err
+	}
+
+	if o.pepperMap != nil && o.keyID != nil {
+		pepper, ok := o.pepperMap[string(o.keyID)]
+		if !ok {
+			return options{}, fmt.Errorf("%w: %q is not in the pepper map", ErrUnknownKeyID, o.keyID)
+		}
+
+		o.pepper = pepper
+	}
+
+	return o, nil
+}
+
+// validate verifies the combination of the configured parameters.
+func (o options) validate() error {
+	if o.memory < minMemoryPerLane*uint32(o.parallelism) {
+		return fmt.Errorf(
+			"%w: memory must be at least %d KiB per unit of parallelism",
+			ErrInvalidOption,
+			minMemoryPerLane,
+		)
+	}
+
+	if o.budget != 0 && o.budget < o.memory {
+		return fmt.Errorf("%w: memory budget is less than the memory parameter", ErrInvalidOption)
+	}
+
+	return nil
+}
+
+// checkSaltLength verifies that the salt of the hashes to create is accepted when they are decoded.
+func (o options) checkSaltLength() error {
+	if o.saltLength < o.minSaltLength {
+		return fmt.Errorf(
+			"%w: salt length is %d bytes, minimum is %d",
+			ErrInvalidOption,
+			o.saltLength,
+			o.minSaltLength,
+		)
+	}
+
+	return nil
+}
+
+// check verifies that the given string is acceptable for hashing.
+func (o options) check(toHash []byte) error {
+	if err := o.checkSaltLength(); err != nil {
+		return err
+	}
+
+	if o.pepperMap != nil && o.keyID == nil {
+		return fmt.Errorf("%w: a key id is required to hash with a pepper map", ErrInvalidOption)
+	}
+
+	if o.rejectEmpty && len(bytes.TrimSpace(toHash)) == 0 {
+		return ErrEmptyPassword
+	}
+
+	if len(toHash) < o.minLength {
+		return fmt.Errorf("%w: got %d bytes, minimum is %d", ErrPasswordTooShort, len(toHash), o.minLength)
+	}
+
+	if len(toHash) > o.maxLength {
+		return fmt.Errorf("%w: got %d bytes, maximum is %d", ErrPasswordTooLong, len(toHash), o.maxLength)
+	}
+
+	if o.minEntropy > 0 && PasswordEntropyBits(string(toHash)) < o.minEntropy {
+		return ErrWeakPassword
+	}
+
+	return nil
+}
+
+func (o options) params() Params {
+	return Params{
+		Variant:     o.variant,
+		Memory:      o.memory,
+		Iterations:  o.iterations,
+		Parallelism: o.parallelism,
+		KeyLength:   o.keyLength,
+		SaltLength:  o.saltLength,
+	}
+}
+
+func (o options) argon2() Argon2 {
+	return Argon2{
+		variant:     o.variant,
+		version:     argon2.Version,
+		memory:      o.memory,
+		iterations:  o.iterations,
+		parallelism: o.parallelism,
+		keyLength:   o.keyLength,
+		budget:      o.budget,
+		encoder:     o.encoder,
+		pepper:      o.pepper,
+		keyID:       o.keyID,
+		isValid:     true,
+
+		fallbackPeppers: o.fallbackPeppers,
+
+		normalize:     o.normalize,
+		form:          o.form,
+		uniformTiming: o.uniformTiming,
+
+		// A hash created by this package is trusted at its own cost.
+		maxCost: o.params(),
+	}
+}
+
+// WithVariant sets the variant of argon2 used for hashing.
+//
+// argon2.VariantD is not supported and results in argon2.ErrUnsupportedVariant.
+func WithVariant(v Variant) Option {
+	return func(o *options) error {
+		if err := v.supported(); err != nil {
+			return err
+		}
+
+		o.variant = v
+
+		return nil
+	}
+}
+
+// WithIterations sets the number of passes over the memory.
+func WithIterations(n uint32) Option {
+	return func(o *options) error {
+		if n == 0 {
+			return fmt.Errorf("%w: iterations must be greater than zero", ErrInvalidOption)
+		}
+
+		o.iterations = n
+
+		return nil
+	}
+}
+
+// WithMemory sets the size of the memory in KiB.
+//
+// It must be at least 8 KiB per unit of parallelism.
+func WithMemory(kib uint32) Option {
+	return func(o *options) error {
+		o.memory = kib
+
+		return nil
+	}
+}
+
+// WithParallelism sets the number of threads.
+func WithParallelism(n uint8) Option {
+	return func(o *options) error {
+		if n == 0 {
+			return fmt.Errorf("%w: parallelism must be greater than zero", ErrInvalidOption)
+		}
+
+		o.parallelism = n
+
+		return nil
+	}
+}
+
+// WithAutoParallelism sets the number of threads to the number of usable CPUs, up to the given limit.
+//
+// The limit defaults to 16, the maximum parallelism accepted when decoding. The chosen value is encoded
+// alongside the other parameters, so the hash verifies on any host.
+func WithAutoParallelism(limit ...uint8) Option {
+	return func(o *options) error {
+		maxThreads := uint8(maxCostParallelism)
+		if len(limit) > 0 {
+			maxThreads = limit[0]
+		}
+
+		if len(limit) > 1 || maxThreads == 0 {
+			return fmt.Errorf("%w: parallelism limit must be a single value greater than zero", ErrInvalidOption)
+		}
+
+		o.parallelism = maxThreads
+		if n := numCPU(); n < int(maxThreads) {
+			o.parallelism = uint8(n)
+		}
+
+		if o.parallelism == 0 {
+			o.parallelism = 1
+		}
+
+		return nil
+	}
+}
+
+// WithKeyLength sets the length of the hashed value in bytes.
+//
+// Argon2 supports lengths of at least 4 bytes, but hashes whose length is outside of the range set by
+// argon2.WithDigestLengthRange, 16 to 64 bytes by default, are rejected when decoded.
+func WithKeyLength(n uint32) Option {
+	return func(o *options) error {
+		if n < minKeyLength {
+			return fmt.Errorf("%w: key length must be at least %d bytes", ErrInvalidOption, minKeyLength)
+		}
+
+		o.keyLength = n
+
+		return nil
+	}
+}
+
+// WithParams sets the variant, memory, iterations, parallelism, key length and salt length at once,
+// e.g. to one of the presets such as argon2.ParamsOWASPMinimum.
+func WithParams(params Params) Option {
+	return func(o *options) error {
+		for _, opt := range params.options() {
+			if err := opt(o); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithMaxMemory sets the maximum memory in KiB that argon2.Calibrate may choose.
+func WithMaxMemory(kib uint32) Option {
+	return func(o *options) error {
+		if kib == 0 {
+			return fmt.Errorf("%w: maximum memory must be greater than zero", ErrInvalidOption)
+		}
+
+		o.maxMemory = kib
+
+		return nil
+	}
+}
+
+// WithSaltLength sets the length of the salt in bytes.
+//
+// Hashes are only created with salts of at least 8 bytes, or the length set by argon2.WithMinSaltLength,
+// since shorter ones are rejected when decoded.
+func WithSaltLength(n uint32) Option {
+	return func(o *options) error {
+		if n == 0 {
+			return fmt.Errorf("%w: salt length must be greater than zero", ErrInvalidOption)
+		}
+
+		o.saltLength = n
+
+		return nil
+	}
+}
+
+// WithMaxVerifyMemory declares the maximum memory in KiB that verifying the hash may use.
+//
+// The budget is encoded alongside the other parameters, so a hash whose memory parameter was
+// later raised beyond it is rejected on comparison.
+func WithMaxVerifyMemory(kib uint32) Option {
+	return func(o *options) error {
+		o.budget = kib
+
+		return nil
+	}
+}
+
+// WithMemoryUnit sets the unit in which the memory parameter of an encoded hash is expressed when decoding.
+//
+// Some foreign encoders write the memory in MiB rather than KiB; decoding such hashes with MiB
+// converts the memory parameter, and the memory budget if any, back to KiB.
+func WithMemoryUnit(u Unit) Option {
+	return func(o *options) error {
+		if u != KiB && u != MiB {
+			return fmt.Errorf("%w: unknown memory unit", ErrInvalidOption)
+		}
+
+		o.memoryUnit = u
+
+		return nil
+	}
+}
+
+// WithEncoder sets the encoder used for the salt and hashed segments of the encoded hash.
+//
+// Hashes created with a non-default encoder produce non-standard PHC strings and must be decoded
+// with the same encoder.
+func WithEncoder(e Encoder) Option {
+	return func(o *options) error {
+		if e == nil {
+			return fmt.Errorf("%w: encoder must not be nil", ErrInvalidOption)
+		}
+
+		o.encoder = e
+
+		return nil
+	}
+}
+
+// WithURLEncoding encodes the salt and hashed segments using URL-safe base64 without padding.
+//
+// Decoding accepts both standard and URL-safe base64 regardless; this only changes how the hash is
+// encoded again, for systems that expect "-" and "_" in place of "+" and "/".
+func WithURLEncoding() Option {
+	return func(o *options) error {
+		o.encoder = base64URLEncoder{}
+
+		return nil
+	}
+}
+
+// WithCanonicalBase64Only rejects encoded hashes whose salt or hashed segments are not exactly
+// the canonical encoding of the bytes they decode to, such as ones with padding or non-zero trailing bits.
+//
+// This prevents several distinct strings from decoding to the same hash.
+func WithCanonicalBase64Only() Option {
+	return func(o *options) error {
+		o.canonicalOnly = true
+
+		return nil
+	}
+}
+
+// WithMinEntropy rejects passwords whose estimated entropy, as given by argon2.PasswordEntropyBits,
+// is below the given number of bits.
+func WithMinEntropy(bits float64) Option {
+	return func(o *options) error {
+		if bits < 0 {
+			return fmt.Errorf("%w: minimum entropy must not be negative", ErrInvalidOption)
+		}
+
+		o.minEntropy = bits
+
+		return nil
+	}
+}
+
+// WithExternalSalt supplies the salt for encoded hashes stored with an empty salt segment.
+//
+// Without it, decoding a hash with an empty salt segment fails. Hashes decoded this way are encoded
+// again with an empty salt segment.
+func WithExternalSalt(salt []byte) Option {
+	return func(o *options) error {
+		if len(salt) == 0 {
+			return fmt.Errorf("%w: external salt must not be empty", ErrInvalidOption)
+		}
+
+		o.externalSalt = append([]byte(nil), salt...)
+
+		return nil
+	}
+}
+
+// WithSalt sets the salt used for hashing, instead of generating a random one.
+//
+// It also sets the salt length to the length of the given salt. Hashing the same value with the same salt
+// and parameters always yields the same encoded hash, which makes it suitable for tests and for migrating
+// hashes with a known salt; otherwise, a reused salt allows precomputed attacks against the hash. As with
+// argon2.WithSaltLength, salts shorter than 8 bytes are rejected when hashing.
+func WithSalt(salt []byte) Option {
+	return func(o *options) error {
+		if len(salt) == 0 {
+			return fmt.Errorf("%w: salt must not be empty", ErrInvalidOption)
+		}
+
+		o.salt = append([]byte(nil), salt...)
+		o.saltLength = uint32(len(salt))
+
+		return nil
+	}
+}
+
+// WithRandReader sets the source of randomness used to generate salts, which defaults to crypto/rand.Reader.
+//
+// Errors returned by the reader are returned by argon2.New.
+func WithRandReader(r io.Reader) Option {
+	return func(o *options) error {
+		if r == nil {
+			return fmt.Errorf("%w: random reader must not be nil", ErrInvalidOption)
+		}
+
+		o.rand = r
+
+		return nil
+	}
+}
+
+// WithAllowedVersions accepts the given argon2 versions when decoding, in addition to argon2.CompatibleVersions.
+//
+// As with argon2.CompatibleVersions, comparing against a hash of a version other than the current
+// argon2.Version fails with argon2.ErrIncompatibleVersion. Unlike it, the versions are only allowed for the
+// decoding they are given to, e.g. to find legacy hashes during a migration; use argon2.Argon2.Version to
+// tell them apart.
+func WithAllowedVersions(versions ...int) Option {
+	return func(o *options) error {
+		o.allowedVersions = append(o.allowedVersions, versions...)
+
+		return nil
+	}
+}
+
+// WithMaxReadSize sets the maximum number of bytes argon2.NewReader reads.
+func WithMaxReadSize(n int64) Option {
+	return func(o *options) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: maximum read size must be greater than zero", ErrInvalidOption)
+		}
+
+		o.maxReadSize = n
+
+		return nil
+	}
+}
+
+// WithDigestLengthRange sets the range of lengths in bytes, inclusive, of the hashed value accepted when decoding.
+//
+// It defaults to 16 to 64 bytes, so that a hash truncated by a storage column too short for it is reported
+// as such rather than never matching.
+func WithDigestLengthRange(minLength, maxLength uint32) Option {
+	return func(o *options) error {
+		if minLength == 0 || minLength > maxLength {
+			return fmt.Errorf("%w: digest length range must be non-empty and start above zero", ErrInvalidOption)
+		}
+
+		o.minDigestLength = minLength
+		o.maxDigestLength = maxLength
+
+		return nil
+	}
+}
+
+// WithMinSaltLength sets the minimum length in bytes of the salt accepted when decoding.
+//
+// It defaults to 8 bytes, the minimum allowed by the argon2 specification, so that a hash with a salt too
+// short to be unique is rejected with argon2.ErrInvalidSalt rather than accepted as a weak credential. Creating
+// a hash with a shorter salt fails with argon2.ErrInvalidOption.
+func WithMinSaltLength(n uint32) Option {
+	return func(o *options) error {
+		if n == 0 {
+			return fmt.Errorf("%w: minimum salt length must be greater than zero", ErrInvalidOption)
+		}
+
+		o.minSaltLength = n
+
+		return nil
+	}
+}
+
+// WithMaxCost sets the maximum memory, iterations and parallelism of the hashes accepted when decoding.
+//
+// Hashes exceeding any of them are rejected with argon2.ErrCostExceeded before any computation takes place,
+// so a malicious or corrupted hash cannot exhaust the resources of the server on comparison. The maximum is
+// kept with the decoded hash and enforced again by Compare. The other fields are ignored. It defaults to
+// 1 GiB of memory, 64 iterations and a parallelism of 16.
+func WithMaxCost(maxCost Params) Option {
+	return func(o *options) error {
+		if maxCost.Memory == 0 || maxCost.Iterations == 0 || maxCost.Parallelism == 0 {
+			return fmt.Errorf("%w: maximum cost must be greater than zero", ErrInvalidOption)
+		}
+
+		o.maxCost = maxCost
+
+		return nil
+	}
+}
+
+// WithRejectEmpty rejects empty and whitespace-only passwords with argon2.ErrEmptyPassword instead of hashing them.
+//
+// It only applies when creating a hash; comparing against an empty password is unaffected.
+func WithRejectEmpty() Option {
+	return func(o *options) error {
+		o.rejectEmpty = true
+
+		return nil
+	}
+}
+
+// WithMinLength rejects passwords shorter than the given number of bytes with argon2.ErrPasswordTooShort.
+func WithMinLength(n int) Option {
+	return func(o *options) error {
+		if n < 0 {
+			return fmt.Errorf("%w: minimum length must not be negative", ErrInvalidOption)
+		}
+
+		o.minLength = n
+
+		return nil
+	}
+}
+
+// WithMaxLength rejects passwords longer than the given number of bytes with argon2.ErrPasswordTooLong.
+//
+// It defaults to 4096 bytes, so that hashing an enormous input cannot be used to waste CPU time.
+// argon2.NewReader is bounded by argon2.WithMaxReadSize instead.
+func WithMaxLength(n int) Option {
+	return func(o *options) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: maximum length must be greater than zero", ErrInvalidOption)
+		}
+
+		o.maxLength = n
+
+		return nil
+	}
+}
+
+// WithWipeInput zeroes the bytes given to argon2.NewBytes once they are hashed, or once hashing fails.
+func WithWipeInput() Option {
+	return func(o *options) error {
+		o.wipeInput = true
+
+		return nil
+	}
+}
+
+// WithPepper mixes the given secret into the hash, both when creating and when decoding it.
+//
+// The password is replaced by HMAC-SHA256(pepper, password) before being hashed. The pepper is never
+// part of the encoded hash, so it must be kept apart from the stored hashes, and the same pepper must
+// be given to argon2.NewByEncoded for the hash to match.
+func WithPepper(pepper []byte) Option {
+	return func(o *options) error {
+		if len(pepper) == 0 {
+			return fmt.Errorf("%w: pepper must not be empty", ErrInvalidOption)
+		}
+
+		o.pepper = append([]byte(nil), pepper...)
+
+		return nil
+	}
+}
+
+// SecretFromEnv reads the pepper from the environment variable named current, and the pepper it replaces
+// from the one named previous, for rotating the pepper without downtime.
+//
+// New hashes are computed with the current pepper, as with argon2.WithPepper. Comparison tries the current
+// pepper first, then the previous one if that variable is set, so hashes computed before the rotation keep
+// matching until they are rehashed. The current variable must be set and not empty.
+func SecretFromEnv(current, previous string) Option {
+	return func(o *options) error {
+		pepper := os.Getenv(current)
+		if pepper == "" {
+			return fmt.Errorf("%w: the environment variable %s must be set", ErrInvalidOption, current)
+		}
+
+		o.pepper = []byte(pepper)
+		o.fallbackPeppers = nil
+
+		if prev := os.Getenv(previous); prev != "" {
+			o.fallbackPeppers = [][]byte{[]byte(prev)}
+		}
+
+		return nil
+	}
+}
+
+// WithPepperRing mixes the peppers of the given ring into the hash, for rotating the pepper by key id.
+//
+// New hashes are computed with the newest pepper and record its key id in the keyid parameter. On decoding,
+// the key id of the hash selects its pepper directly, so a comparison derives a single key; a hash without
+// a known key id tries every pepper of the ring, newest first. Key ids must be unique and not empty.
+func WithPepperRing(ring PepperRing) Option {
+	return func(o *options) error {
+		if len(ring) == 0 {
+			return fmt.Errorf("%w: pepper ring must not be empty", ErrInvalidOption)
+		}
+
+		seen := make(map[string]struct{}, len(ring))
+		peppers := make(PepperRing, 0, len(ring))
+
+		for _, k := range ring {
+			if k.KeyID == "" || len(k.Pepper) == 0 {
+				return fmt.Errorf("%w: pepper ring entries need a key id and a pepper", ErrInvalidOption)
+			}
+
+			if _, ok := seen[k.KeyID]; ok {
+				return fmt.Errorf("%w: duplicate key id %q in the pepper ring", ErrInvalidOption, k.KeyID)
+			}
+
+			seen[k.KeyID] = struct{}{}
+			peppers = append(peppers, KeyedPepper{KeyID: k.KeyID, Pepper: append([]byte(nil), k.Pepper...)})
+		}
+
+		o.pepperRing = peppers
+		o.pepper = peppers[0].Pepper
+		o.keyID = []byte(peppers[0].KeyID)
+		o.fallbackPeppers = nil
+
+		for _, k := range peppers[1:] {
+			o.fallbackPeppers = append(o.fallbackPeppers, k.Pepper)
+		}
+
+		return nil
+	}
+}
+
+// WithPepperMap selects the pepper of each hash by its key id from the given map, for large sets of peppers.
+//
+// On decoding, the key id of the hash, given in its keyid parameter, selects its pepper in a single lookup;
+// comparing against a hash whose key id is not in the map fails with argon2.ErrUnknownKeyID. A hash without
+// a key id is compared with the pepper given by argon2.WithPepper or chosen by argon2.WithKeyID, if any. New
+// hashes require argon2.WithKeyID to choose their pepper.
+func WithPepperMap(peppers map[string][]byte) Option {
+	return func(o *options) error {
+		if len(peppers) == 0 {
+			return fmt.Errorf("%w: pepper map must not be empty", ErrInvalidOption)
+		}
+
+		m := make(map[string][]byte, len(peppers))
+
+		for keyID, pepper := range peppers {
+			if keyID == "" || len(pepper) == 0 {
+				return fmt.Errorf("%w: pepper map entries need a key id and a pepper", ErrInvalidOption)
+			}
+
+			m[keyID] = append([]byte(nil), pepper...)
+		}
+
+		o.pepperMap = m
+
+		return nil
+	}
+}
+
+// WithKeyID records the given key id in the keyid parameter of new hashes, naming the pepper they are
+// computed with. Along with argon2.WithPepperMap, it also chooses that pepper from the map.
+func WithKeyID(keyID string) Option {
+	return func(o *options) error {
+		if keyID == "" {
+			return fmt.Errorf("%w: key id must not be empty", ErrInvalidOption)
+		}
+
+		o.keyID = []byte(keyID)
+
+		return nil
+	}
+}
+
+// WithNormalization normalizes the password to the given Unicode form, both when creating and when decoding
+// the hash.
+//
+// The same password typed on different platforms may reach the server in different forms, e.g. an accented
+// letter as a single code point in NFC or as a letter and a combining mark in NFD. With normalization, both
+// match the same hash. It is off by default, so existing hashes of non-normalized passwords keep matching, and
+// like the pepper, it is not part of the encoded hash, so the same form must be given to argon2.NewByEncoded.
+func WithNormalization(form norm.Form) Option {
+	return func(o *options) error {
+		if form < norm.NFC || form > norm.NFKD {
+			return fmt.Errorf("%w: unknown normalization form %d", ErrInvalidOption, form)
+		}
+
+		o.normalize = true
+		o.form = form
+
+		return nil
+	}
+}
+
+// WithUniformVariantTiming pads every comparison to take at least the given duration.
+//
+// When stored hashes mix variants, e.g. argon2i and argon2id, or parameters, the time taken by a comparison
+// tells them apart. Setting the duration to at least the slowest expected comparison, as measured with
+// argon2.Benchmark, removes that signal. Like the pepper, it is not part of the encoded hash, so it must be
+// given to argon2.NewByEncoded as well.
+func WithUniformVariantTiming(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: uniform timing must be positive", ErrInvalidOption)
+		}
+
+		o.uniformTiming = d
+
+		return nil
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/merajsahebdar/argon2"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestArgon2Options(t *testing.T) {
+	testCases := []struct {
+		opts []argon2.Option
+		want string
+	}{
+		{
+			[]argon2.Option{argon2.WithMemory(16 * 1024), argon2.WithIterations(2)},
+			"$m=16384,t=2,p=2$",
+		},
+		{
+			[]argon2.Option{argon2.WithMemory(8 * 1024), argon2.WithIterations(1), argon2.WithParallelism(1)},
+			"$m=8192,t=1,p=1$",
+		},
+		{
+			[]argon2.Option{argon2.WithMemory(8 * 1024), argon2.WithKeyLength(16), argon2.WithSaltLength(8)},
+			"$m=8192,t=3,p=2$",
+		},
+	}
+
+	for idx, testCase := range testCases {
+		a, err := argon2.New("password", testCase.opts...)
+		if err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+
+			continue
+		}
+
+		if !strings.Contains(a.Encode(), testCase.want) {
+			t.Errorf("in case %d expected %s in %s", idx, testCase.want, a)
+		}
+
+		b, err := argon2.NewByEncoded(a.Encode())
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		if b.Encode() != a.Encode() {
+			t.Errorf("in case %d expected %s, got %s", idx, a, b)
+		}
+
+		if compareErr := b.Compare("password"); compareErr != nil {
+			t.Errorf("in case %d failed to match", idx)
+		}
+	}
+}
+
+func TestArgon2InvalidOptions(t *testing.T) {
+	testCases := [][]argon2.Option{
+		{argon2.WithIterations(0)},
+		{argon2.WithParallelism(0)},
+		{argon2.WithMemory(15), argon2.WithParallelism(2)},
+		{argon2.WithKeyLength(0)},
+		{argon2.WithSaltLength(0)},
+		{argon2.WithMaxVerifyMemory(1024)},
+	}
+
+	for idx, testCase := range testCases {
+		if _, err := argon2.New("password", testCase...); !errors.Is(err, argon2.ErrInvalidOption) {
+			t.Errorf("in case %d expected ErrInvalidOption, got %v", idx, err)
+		}
+	}
+}
+
+func TestWithSalt(t *testing.T) {
+	want := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+	salt := []byte("X9BQMyZQx8SFwwz8")
+
+	for idx := 0; idx < 2; idx++ {
+		a, err := argon2.New("password", argon2.WithSalt(salt))
+		if err != nil {
+			t.Fatalf("in run %d failed to create: %s", idx, err)
+		}
+
+		if got := a.Encode(); got != want {
+			t.Errorf("in run %d expected %s, got %s", idx, want, got)
+		}
+	}
+
+	a, err := argon2.New("password", argon2.WithSalt([]byte("01234567")))
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if a.SaltLength() != 8 {
+		t.Errorf("expected a salt of 8 bytes, got %d", a.SaltLength())
+	}
+
+	if _, err = argon2.New("password", argon2.WithSalt(nil)); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+
+	h, err := argon2.NewHasher(argon2.WithSalt(salt))
+	if err != nil {
+		t.Fatalf("failed to create the hasher: %s", err)
+	}
+
+	for idx := 0; idx < 2; idx++ {
+		x, hashErr := h.Hash("password")
+		if hashErr != nil {
+			t.Fatalf("in run %d failed to hash: %s", idx, hashErr)
+		}
+
+		if got := x.Encode(); got != want {
+			t.Errorf("in run %d expected wiping a previous hash to leave the salt intact, got %s", idx, got)
+		}
+
+		x.Wipe()
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("entropy source unavailable")
+}
+
+func TestWithRandReader(t *testing.T) {
+	want := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	a, err := argon2.New("password", argon2.WithRandReader(bytes.NewReader([]byte("X9BQMyZQx8SFwwz8"))))
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if got := a.Encode(); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	testCases := []argon2.Option{
+		argon2.WithRandReader(failingReader{}),
+		argon2.WithRandReader(bytes.NewReader([]byte("short"))),
+	}
+
+	for idx, testCase := range testCases {
+		if _, err = argon2.New("password", testCase); err == nil {
+			t.Errorf("in case %d expected an error", idx)
+		}
+	}
+}
+
+func TestWithKeyLength(t *testing.T) {
+	testCases := []struct {
+		args    uint32
+		opts    []argon2.Option
+		wantErr bool
+	}{
+		{16, nil, false},
+		{64, nil, false},
+		{8, []argon2.Option{argon2.WithDigestLengthRange(8, 64)}, false},
+		{3, nil, true},
+	}
+
+	for idx, testCase := range testCases {
+		a, err := argon2.New("password", argon2.WithKeyLength(testCase.args))
+		if testCase.wantErr {
+			if !errors.Is(err, argon2.ErrInvalidOption) {
+				t.Errorf("in case %d expected ErrInvalidOption, got %v", idx, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+
+			continue
+		}
+
+		b, err := argon2.NewByEncoded(a.Encode(), testCase.opts...)
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		if b.KeyLength() != testCase.args || len(b.Hash()) != int(testCase.args) {
+			t.Errorf("in case %d expected a key length of %d, got %d", idx, testCase.args, b.KeyLength())
+		}
+
+		if compareErr := b.Compare("password"); compareErr != nil {
+			t.Errorf("in case %d failed to match", idx)
+		}
+	}
+}
+
+func TestWithAutoParallelism(t *testing.T) {
+	testCases := []struct {
+		cpus  int
+		limit []uint8
+		want  uint8
+	}{
+		{1, nil, 1},
+		{8, nil, 8},
+		{32, nil, 16},
+		{32, []uint8{4}, 4},
+		{2, []uint8{4}, 2},
+	}
+
+	for idx, testCase := range testCases {
+		cpus := testCase.cpus
+		restore := argon2.SetNumCPU(func() int { return cpus })
+
+		a, err := argon2.New("password", argon2.WithMemory(256), argon2.WithAutoParallelism(testCase.limit...))
+
+		restore()
+
+		if err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+
+			continue
+		}
+
+		if got := a.Parallelism(); got != testCase.want {
+			t.Errorf("in case %d expected parallelism %d, got %d", idx, testCase.want, got)
+		}
+
+		b, err := argon2.NewByEncoded(a.Encode())
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		if b.Parallelism() != testCase.want {
+			t.Errorf("in case %d expected the encoded parallelism to be %d, got %d", idx, testCase.want, b.Parallelism())
+		}
+
+		if compareErr := b.Compare("password"); compareErr != nil {
+			t.Errorf("in case %d failed to match", idx)
+		}
+	}
+
+	if _, err := argon2.New("password", argon2.WithAutoParallelism(0)); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestWithNormalization(t *testing.T) {
+	composed := "caf\u00e9"
+	decomposed := "cafe\u0301"
+
+	testCases := []struct {
+		opts []argon2.Option
+		args string
+		want string
+		ok   bool
+	}{
+		{[]argon2.Option{argon2.WithNormalization(norm.NFC)}, composed, decomposed, true},
+		{[]argon2.Option{argon2.WithNormalization(norm.NFC)}, decomposed, composed, true},
+		{[]argon2.Option{argon2.WithNormalization(norm.NFD)}, composed, decomposed, true},
+		{[]argon2.Option{argon2.WithNormalization(norm.NFKC)}, "\ufb01le", "file", true},
+		{nil, composed, decomposed, false},
+		{nil, composed, composed, true},
+	}
+
+	for idx, testCase := range testCases {
+		opts := append([]argon2.Option{argon2.WithMemory(64), argon2.WithIterations(1)}, testCase.opts...)
+
+		a, err := argon2.New(testCase.args, opts...)
+		if err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+
+			continue
+		}
+
+		if compareErr := a.Compare(testCase.want); (compareErr == nil) != testCase.ok {
+			t.Errorf("in case %d expected a match to be %t, got %v", idx, testCase.ok, compareErr)
+		}
+
+		b, err := argon2.NewByEncoded(a.Encode(), testCase.opts...)
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		if compareErr := b.Compare(testCase.want); (compareErr == nil) != testCase.ok {
+			t.Errorf("in case %d expected a decoded match to be %t, got %v", idx, testCase.ok, compareErr)
+		}
+	}
+
+	_, err := argon2.New("password", argon2.WithNormalization(norm.Form(42)))
+	if !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestWithUniformVariantTiming(t *testing.T) {
+	const d = 100 * time.Millisecond
+
+	elapsed := make([]time.Duration, 0, 4)
+
+	for _, variant := range []argon2.Variant{argon2.VariantI, argon2.VariantID} {
+		a, err := argon2.New(
+			"password",
+			argon2.WithVariant(variant),
+			argon2.WithMemory(64),
+			argon2.WithIterations(1),
+			argon2.WithUniformVariantTiming(d),
+		)
+		if err != nil {
+			t.Fatalf("failed to create an %s hash: %s", variant, err)
+		}
+
+		b, err := argon2.NewByEncoded(a.Encode(), argon2.WithUniformVariantTiming(d))
+		if err != nil {
+			t.Fatalf("failed to decode an %s hash: %s", variant, err)
+		}
+
+		for _, h := range []argon2.Argon2{a, b} {
+			start := time.Now()
+			if compareErr := h.Compare("password"); compareErr != nil {
+				t.Errorf("expected the %s hash to match, got %v", variant, compareErr)
+			}
+
+			elapsed = append(elapsed, time.Since(start))
+		}
+	}
+
+	for idx, e := range elapsed {
+		if e < d || e > d+d/2 {
+			t.Errorf("in comparison %d expected about %s, took %s", idx, d, e)
+		}
+	}
+
+	_, err := argon2.New("password", argon2.WithUniformVariantTiming(0))
+	if !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrWeakParams is returned when parameters fall below argon2.MinimumParams.
+var ErrWeakParams = errors.New("the parameters are weaker than the minimum")
+
+// Params holds the parameters used to create an argon2 hash.
+type Params struct {
+	Variant     Variant
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	KeyLength   uint32
+	SaltLength  uint32
+}
+
+// Presets of parameters for common security levels, all using argon2id with a 32 bytes key and a 16 bytes salt.
+var (
+	// ParamsOWASPMinimum is the minimum configuration recommended by the OWASP Password Storage Cheat Sheet:
+	// 19 MiB of memory, 2 iterations and a parallelism of 1.
+	ParamsOWASPMinimum = Params{
+		Variant: VariantID, Memory: 19 * 1024, Iterations: 2, Parallelism: 1, KeyLength: 32, SaltLength: 16,
+	}
+
+	// ParamsInteractive matches crypto_pwhash_OPSLIMIT_INTERACTIVE and crypto_pwhash_MEMLIMIT_INTERACTIVE
+	// of libsodium: 64 MiB of memory and 2 iterations, suitable for online operations.
+	ParamsInteractive = Params{
+		Variant: VariantID, Memory: 64 * 1024, Iterations: 2, Parallelism: 1, KeyLength: 32, SaltLength: 16,
+	}
+
+	// ParamsModerate matches crypto_pwhash_OPSLIMIT_MODERATE and crypto_pwhash_MEMLIMIT_MODERATE of
+	// libsodium: 256 MiB of memory and 3 iterations.
+	ParamsModerate = Params{
+		Variant: VariantID, Memory: 256 * 1024, Iterations: 3, Parallelism: 1, KeyLength: 32, SaltLength: 16,
+	}
+
+	// ParamsSensitive matches crypto_pwhash_OPSLIMIT_SENSITIVE and crypto_pwhash_MEMLIMIT_SENSITIVE of
+	// libsodium: 1 GiB of memory and 4 iterations, suitable for highly sensitive data and non-interactive
+	// operations.
+	ParamsSensitive = Params{
+		Variant: VariantID, Memory: 1024 * 1024, Iterations: 4, Parallelism: 1, KeyLength: 32, SaltLength: 16,
+	}
+)
+
+// MinimumParams is the floor below which argon2.Params.Validate reports parameters as too weak.
+//
+// It defaults to the OWASP minimum of 19 MiB of memory and 2 iterations, with 16 bytes keys and salts.
+// The variant is ignored. It is meant to be set once at startup.
+var MinimumParams = Params{Memory: 19 * 1024, Iterations: 2, Parallelism: 1, KeyLength: 16, SaltLength: 16}
+
+// Validate reports whether the parameters are as strong as argon2.MinimumParams.
+//
+// Each of the memory, iterations, parallelism, key length and salt length is compared on its own; the
+// first one below the minimum is named in the returned error, which wraps argon2.ErrWeakParams.
+func (p Params) Validate() error {
+	return p.checkMinimum(MinimumParams)
+}
+
+// checkMinimum verifies that the parameters are as strong as the given minimum, ignoring the variant.
+func (p Params) checkMinimum(minimum Params) error {
+	fields := []struct {
+		name      string
+		got, want uint32
+	}{
+		{"memory", p.Memory, minimum.Memory},
+		{"iterations", p.Iterations, minimum.Iterations},
+		{"parallelism", uint32(p.Parallelism), uint32(minimum.Parallelism)},
+		{"key length", p.KeyLength, minimum.KeyLength},
+		{"salt length", p.SaltLength, minimum.SaltLength},
+	}
+
+	for _, f := range fields {
+		if f.got < f.want {
+			return fmt.Errorf("%w: %s is %d, minimum is %d", ErrWeakParams, f.name, f.got, f.want)
+		}
+	}
+
+	return nil
+}
+
+// NeedsRehash reports whether the hash was created with parameters other than the given ones.
+//
+// It compares the variant, memory, iterations, parallelism and key length; an invalid hash always
+// needs a rehash. Call it after a successful Compare to decide whether to hash the password again.
+func (a Argon2) NeedsRehash(params Params) bool {
+	if !a.isValid {
+		return true
+	}
+
+	return a.variant != params.Variant ||
+		a.memory != params.Memory ||
+		a.iterations != params.Iterations ||
+		a.parallelism != params.Parallelism ||
+		a.keyLength != params.KeyLength
+}
+
+// ParseParams returns the parameters of the given encoded hash, without decoding its salt and hashed value.
+//
+// The key and salt lengths are inferred from the length of their base64 segments. It is cheaper than
+// argon2.NewByEncoded when only the parameters are needed, e.g. to audit many stored hashes at once.
+func ParseParams(encoded string) (Params, error) {
+	vals, err := splitEncoded(encoded)
+	if err != nil {
+		return Params{}, err
+	}
+
+	variant, err := parseVariant(vals[1])
+	if err != nil {
+		return Params{}, &DecodeError{Field: "variant", Value: vals[1], Err: err}
+	}
+
+	version, err := parseVersion(vals[2])
+	if err == nil && !isCompatibleVersion(version, nil) {
+		err = ErrIncompatibleVersion
+	}
+
+	if err != nil {
+		return Params{}, &DecodeError{Field: "version", Value: vals[2], Err: err}
+	}
+
+	var a Argon2
+	if err = a.decodeParams(vals[3]); err != nil {
+		return Params{}, &DecodeError{Field: "params", Value: vals[3], Err: err}
+	}
+
+	return Params{
+		Variant:     variant,
+		Memory:      a.memory,
+		Iterations:  a.iterations,
+		Parallelism: a.parallelism,
+		KeyLength:   uint32(base64.RawStdEncoding.DecodedLen(len(strings.TrimRight(vals[5], "=")))),
+		SaltLength:  uint32(base64.RawStdEncoding.DecodedLen(len(strings.TrimRight(vals[4], "=")))),
+	}, nil
+}
+
+// ParamsHistogram counts the encoded hashes by their parameters, as returned by argon2.ParseParams.
+//
+// Only the prefix of each hash is parsed, so it is suitable for large stores. Hashes that fail to parse
+// are not counted, and their error is returned at the same index.
+func ParamsHistogram(encoded []string) (map[Params]int, []error) {
+	histogram := make(map[Params]int)
+	errs := make([]error, len(encoded))
+
+	for idx, e := range encoded {
+		params, err := ParseParams(e)
+		if err != nil {
+			errs[idx] = err
+
+			continue
+		}
+
+		histogram[params]++
+	}
+
+	return histogram, errs
+}
+
+// ScheduleEntry is an entry of a rotation schedule: the minimum parameters required from a date onwards.
+type ScheduleEntry struct {
+	Effective time.Time
+	Min       Params
+}
+
+// OutdatedSince returns the earliest date of the schedule from which the parameters of the hash are below
+// the required minimum, and whether there is such a date at all.
+//
+// As with argon2.Params.Validate, the memory, iterations, parallelism, key length and salt length are each
+// compared on their own and the variant is ignored. The entries may be in any order. An invalid hash is
+// outdated since the earliest date of the schedule.
+func (a Argon2) OutdatedSince(schedule []ScheduleEntry) (time.Time, bool) {
+	params := Params{
+		Variant:     a.variant,
+		Memory:      a.memory,
+		Iterations:  a.iterations,
+		Parallelism: a.parallelism,
+		KeyLength:   a.keyLength,
+		SaltLength:  uint32(len(a.salt)),
+	}
+
+	var (
+		since    time.Time
+		outdated bool
+	)
+
+	for _, entry := range schedule {
+		if a.isValid && params.checkMinimum(entry.Min) == nil {
+			continue
+		}
+
+		if !outdated || entry.Effective.Before(since) {
+			since, outdated = entry.Effective, true
+		}
+	}
+
+	return since, outdated
+}
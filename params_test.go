@@ -0,0 +1,387 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestArgon2NeedsRehash(t *testing.T) {
+	a, err := argon2.NewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+	)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	current := argon2.Params{
+		Variant:     argon2.VariantID,
+		Memory:      65536,
+		Iterations:  3,
+		Parallelism: 2,
+		KeyLength:   32,
+		SaltLength:  16,
+	}
+
+	testCases := []struct {
+		args func(p *argon2.Params)
+		want bool
+	}{
+		{func(p *argon2.Params) {}, false},
+		{func(p *argon2.Params) { p.SaltLength = 32 }, false},
+		{func(p *argon2.Params) { p.Memory = 128 * 1024 }, true},
+		{func(p *argon2.Params) { p.Iterations = 4 }, true},
+		{func(p *argon2.Params) { p.Parallelism = 4 }, true},
+		{func(p *argon2.Params) { p.KeyLength = 64 }, true},
+		{func(p *argon2.Params) { p.Variant = argon2.VariantI }, true},
+	}
+
+	for idx, testCase := range testCases {
+		params := current
+		testCase.args(&params)
+
+		if got := a.NeedsRehash(params); got != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, got)
+		}
+	}
+
+	if !(argon2.Argon2{}).NeedsRehash(current) {
+		t.Errorf("expected an invalid hash to need a rehash")
+	}
+}
+
+func TestArgon2Getters(t *testing.T) {
+	testCases := []struct {
+		deps        argon2.Argon2
+		memory      uint32
+		iterations  uint32
+		parallelism uint8
+		keyLength   uint32
+		saltLength  int
+	}{
+		{
+			argon2.MustNew("password", argon2.WithMemory(8*1024), argon2.WithIterations(1), argon2.WithParallelism(1)),
+			8 * 1024, 1, 1, 32, 16,
+		},
+		{
+			mustNewByEncoded(
+				"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			),
+			65536, 3, 2, 32, 16,
+		},
+		{argon2.Argon2{}, 0, 0, 0, 0, 0},
+	}
+
+	for idx, testCase := range testCases {
+		a := testCase.deps
+
+		if a.Memory() != testCase.memory ||
+			a.Iterations() != testCase.iterations ||
+			a.Parallelism() != testCase.parallelism ||
+			a.KeyLength() != testCase.keyLength ||
+			a.SaltLength() != testCase.saltLength {
+			t.Errorf(
+				"in case %d got m=%d,t=%d,p=%d,key=%d,salt=%d",
+				idx,
+				a.Memory(),
+				a.Iterations(),
+				a.Parallelism(),
+				a.KeyLength(),
+				a.SaltLength(),
+			)
+		}
+	}
+}
+
+func mustNewByEncoded(encoded string) argon2.Argon2 {
+	a, err := argon2.NewByEncoded(encoded)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+func TestParamsPresets(t *testing.T) {
+	testCases := []struct {
+		args argon2.Params
+		want string
+	}{
+		{argon2.ParamsOWASPMinimum, "$argon2id$v=19$m=19456,t=2,p=1$"},
+		{argon2.ParamsInteractive, "$argon2id$v=19$m=65536,t=2,p=1$"},
+		{argon2.ParamsModerate, "$argon2id$v=19$m=262144,t=3,p=1$"},
+		{argon2.ParamsSensitive, "$argon2id$v=19$m=1048576,t=4,p=1$"},
+	}
+
+	// The derivation is stubbed out, so the heavier presets are checked without hashing up to 1 GiB.
+	k := &recordingKDF{}
+
+	argon2.SetKDF(k)
+	defer argon2.SetKDF(nil)
+
+	for idx, testCase := range testCases {
+		k.calls = nil
+
+		a, err := argon2.New("password", argon2.WithParams(testCase.args))
+		if err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+
+			continue
+		}
+
+		want := recordedCall{
+			testCase.args.Iterations,
+			testCase.args.Memory,
+			testCase.args.Parallelism,
+			testCase.args.KeyLength,
+		}
+		if len(k.calls) != 1 || k.calls[0] != want {
+			t.Errorf("in case %d expected a single derivation with %+v, got %+v", idx, want, k.calls)
+		}
+
+		if got := a.Encode(); !strings.HasPrefix(got, testCase.want) {
+			t.Errorf("in case %d expected %s to start with %s", idx, got, testCase.want)
+		}
+
+		if a.NeedsRehash(testCase.args) {
+			t.Errorf("in case %d expected the hash not to need a rehash", idx)
+		}
+	}
+
+	if _, err := argon2.New("password", argon2.WithParams(argon2.Params{})); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	testCases := []struct {
+		args string
+		want argon2.Params
+	}{
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			argon2.Params{Memory: 65536, Iterations: 3, Parallelism: 2, KeyLength: 32, SaltLength: 16},
+		},
+		{
+			"$argon2i$v=19$m=19456,t=2,p=1$WDlCUU15WlF4OFNGd3d6OA==$0nJpNUfEq3ELzeoGwcd+cA==",
+			argon2.Params{Variant: argon2.VariantI, Memory: 19456, Iterations: 2, Parallelism: 1, KeyLength: 16, SaltLength: 16},
+		},
+	}
+
+	for idx, testCase := range testCases {
+		got, err := argon2.ParseParams(testCase.args)
+		if err != nil {
+			t.Errorf("in case %d failed to parse: %s", idx, err)
+
+			continue
+		}
+
+		if got != testCase.want {
+			t.Errorf("in case %d expected %+v, got %+v", idx, testCase.want, got)
+		}
+
+		if a := mustNewByEncoded(testCase.args); a.NeedsRehash(got) {
+			t.Errorf("in case %d expected the parameters to match the decoded hash", idx)
+		}
+	}
+
+	for idx, args := range []string{"malformed", "$argon2id$v=19$m=65536,t=x,p=2$WDlCUU15WlF4OFNGd3d6OA$"} {
+		if _, err := argon2.ParseParams(args); !errors.Is(err, argon2.ErrInvalidEncodedHash) {
+			t.Errorf("in case %d expected ErrInvalidEncodedHash, got %v", idx, err)
+		}
+	}
+}
+
+func TestParseParamsErrors(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	testCases := []struct {
+		args    string
+		wantErr error
+	}{
+		{strings.ReplaceAll(encoded, "$", ":"), argon2.ErrWrongSeparator},
+		{"x" + encoded, argon2.ErrInvalidEncodedHash},
+	}
+
+	for idx, testCase := range testCases {
+		_, err := argon2.ParseParams(testCase.args)
+		if !errors.Is(err, testCase.wantErr) {
+			t.Errorf("in case %d expected error %v, got %v", idx, testCase.wantErr, err)
+		}
+
+		_, decodeErr := argon2.NewByEncoded(testCase.args)
+		if err == nil || decodeErr == nil || err.Error() != decodeErr.Error() {
+			t.Errorf("in case %d expected the same error as NewByEncoded %v, got %v", idx, decodeErr, err)
+		}
+	}
+
+	var decodeErr *argon2.DecodeError
+	if _, err := argon2.ParseParams("x" + encoded); !errors.As(err, &decodeErr) || decodeErr.Field != "prefix" {
+		t.Errorf("expected a DecodeError on the prefix, got %v", err)
+	}
+}
+
+func TestParamsHistogram(t *testing.T) {
+	current := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+	weak := strings.Replace(current, "t=3", "t=1", 1)
+
+	histogram, errs := argon2.ParamsHistogram([]string{current, weak, "malformed", current})
+
+	want := map[argon2.Params]int{
+		{Memory: 65536, Iterations: 3, Parallelism: 2, KeyLength: 32, SaltLength: 16}: 2,
+		{Memory: 65536, Iterations: 1, Parallelism: 2, KeyLength: 32, SaltLength: 16}: 1,
+	}
+
+	if len(histogram) != len(want) {
+		t.Errorf("expected %d configurations, got %v", len(want), histogram)
+	}
+
+	for params, n := range want {
+		if histogram[params] != n {
+			t.Errorf("expected %d hashes with %+v, got %d", n, params, histogram[params])
+		}
+	}
+
+	if len(errs) != 4 || errs[0] != nil || errs[1] != nil || errs[3] != nil {
+		t.Errorf("expected errors only for the malformed hash, got %v", errs)
+	}
+
+	if len(errs) == 4 && !errors.Is(errs[2], argon2.ErrInvalidEncodedHash) {
+		t.Errorf("expected ErrInvalidEncodedHash for the malformed hash, got %v", errs[2])
+	}
+}
+
+func BenchmarkParseParams(b *testing.B) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	b.Run("ParseParams", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := argon2.ParseParams(encoded); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("NewByEncoded", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := argon2.NewByEncoded(encoded); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestParamsValidate(t *testing.T) {
+	floor := argon2.Params{Memory: 19 * 1024, Iterations: 2, Parallelism: 1, KeyLength: 16, SaltLength: 16}
+
+	testCases := []struct {
+		args      argon2.Params
+		wantField string
+	}{
+		{floor, ""},
+		{argon2.ParamsOWASPMinimum, ""},
+		{argon2.Defaults(), ""},
+		{argon2.Params{Memory: 19*1024 - 1, Iterations: 2, Parallelism: 1, KeyLength: 16, SaltLength: 16}, "memory"},
+		{argon2.Params{Memory: 19 * 1024, Iterations: 1, Parallelism: 1, KeyLength: 16, SaltLength: 16}, "iterations"},
+		{argon2.Params{Memory: 19 * 1024, Iterations: 2, Parallelism: 0, KeyLength: 16, SaltLength: 16}, "parallelism"},
+		{argon2.Params{Memory: 19 * 1024, Iterations: 2, Parallelism: 1, KeyLength: 8, SaltLength: 16}, "key length"},
+		{argon2.Params{Memory: 19 * 1024, Iterations: 2, Parallelism: 1, KeyLength: 16, SaltLength: 8}, "salt length"},
+	}
+
+	for idx, testCase := range testCases {
+		err := testCase.args.Validate()
+		if testCase.wantField == "" {
+			if err != nil {
+				t.Errorf("in case %d expected no error, got %s", idx, err)
+			}
+
+			continue
+		}
+
+		if !errors.Is(err, argon2.ErrWeakParams) || !strings.Contains(err.Error(), testCase.wantField) {
+			t.Errorf("in case %d expected ErrWeakParams naming the %s, got %v", idx, testCase.wantField, err)
+		}
+	}
+
+	defaults := argon2.MinimumParams
+	argon2.MinimumParams.Memory = 64 * 1024
+
+	defer func() {
+		argon2.MinimumParams = defaults
+	}()
+
+	if err := floor.Validate(); !errors.Is(err, argon2.ErrWeakParams) {
+		t.Errorf("expected ErrWeakParams with a raised minimum, got %v", err)
+	}
+}
+
+func TestArgon2OutdatedSince(t *testing.T) {
+	first := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	second := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	schedule := []argon2.ScheduleEntry{
+		{Effective: second, Min: argon2.Params{Memory: 64 * 1024, Iterations: 3, Parallelism: 1, KeyLength: 32}},
+		{Effective: first, Min: argon2.Params{Memory: 19 * 1024, Iterations: 2, Parallelism: 1, KeyLength: 16}},
+	}
+
+	testCases := []struct {
+		deps         argon2.Argon2
+		want         time.Time
+		wantOutdated bool
+	}{
+		{
+			mustNewByEncoded(
+				"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			),
+			time.Time{},
+			false,
+		},
+		{
+			mustNewByEncoded(
+				"$argon2id$v=19$m=19456,t=2,p=1$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			),
+			second,
+			true,
+		},
+		{
+			mustNewByEncoded(
+				"$argon2id$v=19$m=8192,t=2,p=1$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			),
+			first,
+			true,
+		},
+		{argon2.Argon2{}, first, true},
+	}
+
+	for idx, testCase := range testCases {
+		got, outdated := testCase.deps.OutdatedSince(schedule)
+		if outdated != testCase.wantOutdated || !got.Equal(testCase.want) {
+			t.Errorf("in case %d expected %s, %t, got %s, %t", idx, testCase.want, testCase.wantOutdated, got, outdated)
+		}
+	}
+
+	if _, outdated := argon2.MustNew("password").OutdatedSince(nil); outdated {
+		t.Errorf("expected no date without a schedule")
+	}
+}
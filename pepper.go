@@ -0,0 +1,56 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+// KeyedPepper is a pepper named by a key id, which is recorded in the keyid parameter of the hashes
+// computed with it.
+type KeyedPepper struct {
+	KeyID  string
+	Pepper []byte
+}
+
+// PepperRing lists the peppers of a rotation, newest first.
+//
+// New hashes are computed with the newest pepper and record its key id. On comparison, the pepper named
+// by the key id of the hash is tried alone; a hash without a key id, or with one that is not in the ring,
+// tries every pepper in turn.
+type PepperRing []KeyedPepper
+
+// selectPepper narrows the peppers tried on comparison to the one named by the key id of the hash, if any.
+//
+// With a pepper map, a key id that is not in the map leaves the hash unable to be compared.
+func (o options) selectPepper(a *Argon2) {
+	if a.keyID == nil {
+		return
+	}
+
+	if o.pepperMap != nil {
+		pepper, ok := o.pepperMap[string(a.keyID)]
+		a.pepper = pepper
+		a.fallbackPeppers = nil
+		a.unknownKeyID = !ok
+
+		return
+	}
+
+	for _, k := range o.pepperRing {
+		if k.KeyID == string(a.keyID) {
+			a.pepper = k.Pepper
+			a.fallbackPeppers = nil
+
+			return
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestArgon2WithPepper(t *testing.T) {
+	pepper := []byte("server-side secret")
+
+	a, err := argon2.New("password", argon2.WithPepper(pepper))
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if strings.Contains(a.Encode(), "server-side secret") {
+		t.Errorf("expected the pepper to not be encoded, got %s", a)
+	}
+
+	testCases := []struct {
+		opts []argon2.Option
+		want bool
+	}{
+		{[]argon2.Option{argon2.WithPepper(pepper)}, true},
+		{nil, false},
+		{[]argon2.Option{argon2.WithPepper([]byte("another secret"))}, false},
+	}
+
+	for idx, testCase := range testCases {
+		b, err := argon2.NewByEncoded(a.Encode(), testCase.opts...)
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		if got := b.Compare("password") == nil; got != testCase.want {
+			t.Errorf("in case %d expected match to be %t, got %t", idx, testCase.want, got)
+		}
+	}
+}
+
+func TestSecretFromEnv(t *testing.T) {
+	const current, previous = "TEST_ARGON2_PEPPER", "TEST_ARGON2_PEPPER_PREVIOUS"
+
+	opts := []argon2.Option{argon2.WithMemory(64), argon2.WithIterations(1), argon2.SecretFromEnv(current, previous)}
+
+	t.Setenv(current, "first secret")
+	t.Setenv(previous, "")
+
+	a, err := argon2.New("password", opts...)
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	// Rotate: the current secret becomes the previous one.
+	t.Setenv(current, "second secret")
+	t.Setenv(previous, "first secret")
+
+	b, err := argon2.New("password", opts...)
+	if err != nil {
+		t.Fatalf("failed to create after the rotation: %s", err)
+	}
+
+	testCases := []struct {
+		encoded string
+		opts    []argon2.Option
+		want    bool
+	}{
+		{a.Encode(), []argon2.Option{argon2.SecretFromEnv(current, previous)}, true},
+		{b.Encode(), []argon2.Option{argon2.SecretFromEnv(current, previous)}, true},
+		{a.Encode(), []argon2.Option{argon2.WithPepper([]byte("second secret"))}, false},
+		{b.Encode(), []argon2.Option{argon2.WithPepper([]byte("second secret"))}, true},
+		{a.Encode(), []argon2.Option{argon2.SecretFromEnv(current, "TEST_ARGON2_PEPPER_UNSET")}, false},
+	}
+
+	for idx, testCase := range testCases {
+		h, decodeErr := argon2.NewByEncoded(testCase.encoded, testCase.opts...)
+		if decodeErr != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, decodeErr)
+
+			continue
+		}
+
+		if got := h.Compare("password") == nil; got != testCase.want {
+			t.Errorf("in case %d expected match to be %t, got %t", idx, testCase.want, got)
+		}
+
+		if got := argon2.CompareAny("password", []argon2.Argon2{h}); got != testCase.want {
+			t.Errorf("in case %d expected any match to be %t, got %t", idx, testCase.want, got)
+		}
+	}
+
+	rotated, err := argon2.NewByEncoded(a.Encode(), argon2.SecretFromEnv(current, previous))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if mismatch := rotated.Compare("secret"); !errors.Is(mismatch, argon2.ErrMismatched) {
+		t.Errorf("expected ErrMismatched with both secrets tried, got %v", mismatch)
+	}
+
+	t.Setenv(current, "")
+
+	if _, err = argon2.New("password", opts...); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected error %v, got %v", argon2.ErrInvalidOption, err)
+	}
+}
+
+func TestWithPepperRing(t *testing.T) {
+	ring := argon2.PepperRing{
+		{KeyID: "k3", Pepper: []byte("third secret")},
+		{KeyID: "k2", Pepper: []byte("second secret")},
+		{KeyID: "k1", Pepper: []byte("first secret")},
+	}
+	cheap := []argon2.Option{argon2.WithMemory(64), argon2.WithIterations(1)}
+
+	newest := argon2.MustNew("password", append(cheap, argon2.WithPepperRing(ring))...)
+	if !strings.Contains(newest.Encode(), ",keyid=azM$") {
+		t.Errorf("expected the key id of the newest pepper, got %s", newest)
+	}
+
+	middle := argon2.MustNew("password", append(cheap, argon2.WithPepperRing(ring[1:]))...)
+	unnamed := argon2.MustNew("password", append(cheap, argon2.WithPepper(ring[1].Pepper))...)
+
+	testCases := []struct {
+		encoded string
+		want    int
+	}{
+		{newest.Encode(), 1},
+		{middle.Encode(), 1},
+		{unnamed.Encode(), 2},
+		{strings.Replace(middle.Encode(), ",keyid=azI$", ",keyid=azk$", 1), 2},
+	}
+
+	for idx, testCase := range testCases {
+		a, err := argon2.NewByEncoded(testCase.encoded, argon2.WithPepperRing(ring))
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		k := &countingKDF{}
+		argon2.SetKDF(k)
+
+		if compareErr := a.Compare("password"); compareErr != nil {
+			t.Errorf("in case %d failed to match: %s", idx, compareErr)
+		}
+
+		argon2.SetKDF(nil)
+
+		if k.calls != testCase.want {
+			t.Errorf("in case %d expected %d attempts, got %d", idx, testCase.want, k.calls)
+		}
+	}
+
+	for idx, invalid := range []argon2.PepperRing{
+		nil,
+		{{KeyID: "", Pepper: []byte("secret")}},
+		{{KeyID: "k1", Pepper: nil}},
+		{{KeyID: "k1", Pepper: []byte("secret")}, {KeyID: "k1", Pepper: []byte("another secret")}},
+	} {
+		if _, err := argon2.New("password", argon2.WithPepperRing(invalid)); !errors.Is(err, argon2.ErrInvalidOption) {
+			t.Errorf("in case %d expected error %v, got %v", idx, argon2.ErrInvalidOption, err)
+		}
+	}
+}
+
+func TestWithPepperMap(t *testing.T) {
+	peppers := map[string][]byte{
+		"k1": []byte("first secret"),
+		"k2": []byte("second secret"),
+	}
+	cheap := []argon2.Option{argon2.WithMemory(64), argon2.WithIterations(1)}
+
+	a, err := argon2.New("password", append(cheap, argon2.WithPepperMap(peppers), argon2.WithKeyID("k2"))...)
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if !strings.Contains(a.Encode(), ",keyid=azI$") {
+		t.Errorf("expected the key id to be encoded, got %s", a)
+	}
+
+	unknown := strings.Replace(a.Encode(), "keyid=azI", "keyid=azk", 1)
+	swapped := map[string][]byte{"k2": peppers["k1"]}
+
+	testCases := []struct {
+		encoded string
+		opts    []argon2.Option
+		wantErr error
+	}{
+		{a.Encode(), []argon2.Option{argon2.WithPepperMap(peppers)}, nil},
+		{a.Encode(), []argon2.Option{argon2.WithPepper(peppers["k2"])}, nil},
+		{a.Encode(), []argon2.Option{argon2.WithPepperMap(swapped)}, argon2.ErrMismatched},
+		{unknown, []argon2.Option{argon2.WithPepperMap(peppers)}, argon2.ErrUnknownKeyID},
+	}
+
+	for idx, testCase := range testCases {
+		b, decodeErr := argon2.NewByEncoded(testCase.encoded, testCase.opts...)
+		if decodeErr != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, decodeErr)
+
+			continue
+		}
+
+		if compareErr := b.Compare("password"); !errors.Is(compareErr, testCase.wantErr) {
+			t.Errorf("in case %d expected error %v, got %v", idx, testCase.wantErr, compareErr)
+		}
+	}
+
+	h, err := argon2.NewHasher(argon2.WithPepperMap(peppers))
+	if err != nil {
+		t.Fatalf("failed to create the hasher: %s", err)
+	}
+
+	if _, err = h.Verify(unknown, "password"); !errors.Is(err, argon2.ErrUnknownKeyID) {
+		t.Errorf("expected error %v, got %v", argon2.ErrUnknownKeyID, err)
+	}
+
+	if _, err = argon2.New("password", argon2.WithPepperMap(peppers)); !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected error %v without a key id, got %v", argon2.ErrInvalidOption, err)
+	}
+
+	_, err = argon2.New("password", argon2.WithPepperMap(peppers), argon2.WithKeyID("k9"))
+	if !errors.Is(err, argon2.ErrUnknownKeyID) {
+		t.Errorf("expected error %v, got %v", argon2.ErrUnknownKeyID, err)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// ErrPoolClosed is returned when a hash is submitted to a closed pool.
+var ErrPoolClosed = errors.New("the pool is closed")
+
+// Result holds the outcome of a hash computed by a pool.
+type Result struct {
+	Argon2 Argon2
+	Err    error
+}
+
+type poolJob struct {
+	toHash string
+	result chan<- Result
+}
+
+// Pool computes hashes on a bounded number of workers.
+//
+// At most workers hashes are computed at the same time, so the memory used by
+// the pool is capped to workers times the configured memory parameter.
+type Pool struct {
+	opts []Option
+	jobs chan poolJob
+	mu   sync.RWMutex
+	wg   sync.WaitGroup
+
+	closed bool
+}
+
+// NewPool returns a new argon2.Pool with the given number of workers hashing with the given options.
+func NewPool(workers int, opts ...Option) (*Pool, error) {
+	if workers <= 0 {
+		return nil, fmt.Errorf("%w: workers must be greater than zero", ErrInvalidOption)
+	}
+
+	if _, err := newOptions(opts); err != nil {
+		return nil, err
+	}
+
+	p := &Pool{
+		opts: opts,
+		jobs: make(chan poolJob, workers),
+	}
+
+	p.wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+
+	return p, nil
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for j := range p.jobs {
+		a, err := New(j.toHash, p.opts...)
+		j.result <- Result{Argon2: a, Err: err}
+	}
+}
+
+// Submit queues the given string to be hashed and returns a channel receiving the result.
+//
+// Submit blocks while the queue is full.
+func (p *Pool) Submit(toHash string) <-chan Result {
+	result := make(chan Result, 1)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		result <- Result{Err: ErrPoolClosed}
+
+		return result
+	}
+
+	p.jobs <- poolJob{toHash: toHash, result: result}
+
+	return result
+}
+
+// Close stops accepting new hashes and waits for the queued ones to finish.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+// VerifyRequest asks to verify a candidate against an encoded hash.
+type VerifyRequest struct {
+	Encoded   string
+	Candidate string
+}
+
+// VerifyResult holds the outcome of a verification made by argon2.VerifyStream.
+type VerifyResult struct {
+	Request VerifyRequest
+	Match   bool
+	Err     error
+}
+
+// VerifyStream verifies the requests received from in on the given number of workers and sends the
+// results to out, in no particular order.
+//
+// At most workers verifications run at the same time. Decoding errors are reported in the result,
+// while a mismatch is reported as Match being false. It returns and closes out once in is closed and
+// drained, or once ctx is done.
+func VerifyStream(ctx context.Context, in <-chan VerifyRequest, out chan<- VerifyResult, workers int) {
+	defer close(out)
+
+	if workers <= 0 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for {
+				var req VerifyRequest
+				var ok bool
+
+				select {
+				case <-ctx.Done():
+					return
+				case req, ok = <-in:
+					if !ok {
+						return
+					}
+				}
+
+				result := verify(req)
+
+				select {
+				case <-ctx.Done():
+					return
+				case out <- result:
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// BatchResult holds the outcome of a verification made by argon2.CompareBatch.
+type BatchResult struct {
+	Index int
+	Match bool
+	Err   error
+}
+
+// CompareBatch verifies the given requests and returns their results in the same order.
+//
+// At most GOMAXPROCS verifications run at the same time, so the memory used is capped to that many
+// times the memory parameter of the hashes. Decoding errors are reported in the result of the entry
+// at fault, while a mismatch is reported as Match being false. Once ctx is done, the remaining
+// requests are not verified and their results hold the context's error.
+func CompareBatch(ctx context.Context, reqs []VerifyRequest) []BatchResult {
+	results := make([]BatchResult, len(reqs))
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(reqs) {
+		workers = len(reqs)
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for idx := range indexes {
+				result := verify(reqs[idx])
+				results[idx] = BatchResult{Index: idx, Match: result.Match, Err: result.Err}
+			}
+		}()
+	}
+
+	for idx := range reqs {
+		if err := ctx.Err(); err != nil {
+			results[idx] = BatchResult{Index: idx, Err: fmt.Errorf("failed to verify: %w", err)}
+
+			continue
+		}
+
+		indexes <- idx
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+func verify(req VerifyRequest) VerifyResult {
+	match, err := CompareEncoded(req.Encoded, req.Candidate)
+
+	return VerifyResult{Request: req, Match: match, Err: err}
+}
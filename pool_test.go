@@ -0,0 +1,149 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestPool(t *testing.T) {
+	p, err := argon2.NewPool(2)
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	args := []string{"password", "secret", "another", "one more"}
+
+	results := make([]<-chan argon2.Result, len(args))
+	for idx, arg := range args {
+		results[idx] = p.Submit(arg)
+	}
+
+	for idx, result := range results {
+		r := <-result
+		if r.Err != nil {
+			t.Errorf("in case %d failed to hash: %s", idx, r.Err)
+
+			continue
+		}
+
+		if compareErr := r.Argon2.Compare(args[idx]); compareErr != nil {
+			t.Errorf("in case %d failed to match", idx)
+		}
+	}
+
+	p.Close()
+
+	if r := <-p.Submit("password"); !errors.Is(r.Err, argon2.ErrPoolClosed) {
+		t.Errorf("expected ErrPoolClosed after close, got %v", r.Err)
+	}
+}
+
+func TestVerifyStream(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	testCases := []struct {
+		args    argon2.VerifyRequest
+		want    bool
+		wantErr bool
+	}{
+		{argon2.VerifyRequest{Encoded: encoded, Candidate: "password"}, true, false},
+		{argon2.VerifyRequest{Encoded: encoded, Candidate: "secret"}, false, false},
+		{argon2.VerifyRequest{Encoded: "malformed", Candidate: "password"}, false, true},
+	}
+
+	in := make(chan argon2.VerifyRequest)
+	out := make(chan argon2.VerifyResult)
+
+	go argon2.VerifyStream(context.Background(), in, out, 2)
+
+	go func() {
+		for _, testCase := range testCases {
+			in <- testCase.args
+		}
+
+		close(in)
+	}()
+
+	results := make(map[argon2.VerifyRequest]argon2.VerifyResult)
+	for result := range out {
+		results[result.Request] = result
+	}
+
+	for idx, testCase := range testCases {
+		result, ok := results[testCase.args]
+		if !ok {
+			t.Errorf("in case %d got no result", idx)
+
+			continue
+		}
+
+		if result.Match != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, result.Match)
+		}
+
+		if (result.Err != nil) != testCase.wantErr {
+			t.Errorf("in case %d unexpected error: %v", idx, result.Err)
+		}
+	}
+}
+
+func TestCompareBatch(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	testCases := []struct {
+		args      argon2.VerifyRequest
+		wantMatch bool
+		wantErr   bool
+	}{
+		{argon2.VerifyRequest{Encoded: encoded, Candidate: "password"}, true, false},
+		{argon2.VerifyRequest{Encoded: encoded, Candidate: "secret"}, false, false},
+		{argon2.VerifyRequest{Encoded: "malformed", Candidate: "password"}, false, true},
+		{argon2.VerifyRequest{Encoded: encoded, Candidate: "password"}, true, false},
+	}
+
+	reqs := make([]argon2.VerifyRequest, 0, len(testCases))
+	for _, testCase := range testCases {
+		reqs = append(reqs, testCase.args)
+	}
+
+	results := argon2.CompareBatch(context.Background(), reqs)
+	if len(results) != len(testCases) {
+		t.Fatalf("expected %d results, got %d", len(testCases), len(results))
+	}
+
+	for idx, testCase := range testCases {
+		if results[idx].Index != idx {
+			t.Errorf("in case %d expected index %d, got %d", idx, idx, results[idx].Index)
+		}
+
+		if results[idx].Match != testCase.wantMatch || (results[idx].Err != nil) != testCase.wantErr {
+			t.Errorf("in case %d expected %t, %t, got %+v", idx, testCase.wantMatch, testCase.wantErr, results[idx])
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for idx, result := range argon2.CompareBatch(ctx, reqs) {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("in case %d expected context.Canceled, got %v", idx, result.Err)
+		}
+	}
+}
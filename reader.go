@@ -0,0 +1,104 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// defaultMaxReadSize is the maximum number of bytes argon2.NewReader reads by default.
+const defaultMaxReadSize = 1024 * 1024
+
+// ErrInputTooLarge is returned when a reader yields more bytes than the configured maximum.
+var ErrInputTooLarge = errors.New("the input exceeds the maximum size")
+
+// NewReader returns a new argon2.Argon2 by hashing all the bytes read from r.
+//
+// It reads at most the size set by argon2.WithMaxReadSize, 1 MiB by default, and fails with
+// argon2.ErrInputTooLarge beyond it; argon2.WithMaxLength does not apply. The bytes read are zeroed once
+// they are hashed.
+func NewReader(r io.Reader, opts ...Option) (Argon2, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return Argon2{}, err
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r, o.maxReadSize+1))
+	defer wipe(b)
+
+	if err != nil {
+		return Argon2{}, fmt.Errorf("failed to read: %w", err)
+	}
+
+	if int64(len(b)) > o.maxReadSize {
+		return Argon2{}, fmt.Errorf("%w: read more than %d bytes", ErrInputTooLarge, o.maxReadSize)
+	}
+
+	o.maxLength = len(b)
+
+	return newBytes(b, o)
+}
+
+// DecodeAll decodes every encoded hash read from r, one per line.
+//
+// Lines may end with either "\n" or "\r\n". Blank lines are skipped.
+func DecodeAll(r io.Reader) ([]Argon2, error) {
+	var all []Argon2
+
+	err := ForEachEncoded(r, func(lineNum int, a Argon2, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to decode line %d: %w", lineNum, err)
+		}
+
+		all = append(all, a)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+// ForEachEncoded decodes the encoded hashes read from r line by line, calling fn for each of them
+// with its line number, starting from 1, and its decoding result.
+//
+// Lines may end with either "\n" or "\r\n". Blank lines are skipped. If fn returns an error,
+// the iteration stops and that error is returned.
+func ForEachEncoded(r io.Reader, fn func(lineNum int, a Argon2, err error) error) error {
+	s := bufio.NewScanner(r)
+	for lineNum := 1; s.Scan(); lineNum++ {
+		encoded := strings.TrimSpace(s.Text())
+		if encoded == "" {
+			continue
+		}
+
+		a, decodeErr := NewByEncoded(encoded)
+		if err := fn(lineNum, a, decodeErr); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("failed to read: %w", err)
+	}
+
+	return nil
+}
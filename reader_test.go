@@ -0,0 +1,162 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestArgon2DecodeCRLF(t *testing.T) {
+	a, err := argon2.NewByEncoded(
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8\r\n",
+	)
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if compareErr := a.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+}
+
+func TestArgon2DecodeCRLFCanonical(t *testing.T) {
+	encoded := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8"
+
+	for idx, suffix := range []string{"\n", "\r\n"} {
+		a, err := argon2.NewByEncoded(encoded+suffix, argon2.WithCanonicalBase64Only())
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		if a.Encode() != encoded {
+			t.Errorf("in case %d expected %s, got %s", idx, encoded, a.Encode())
+		}
+
+		if ok, verifyErr := argon2.Verify(encoded+suffix, "password"); !ok || verifyErr != nil {
+			t.Errorf("in case %d failed to verify: %v", idx, verifyErr)
+		}
+	}
+}
+
+func TestDecodeAll(t *testing.T) {
+	content := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8\r\n" +
+		"\r\n" +
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$parPWxJrAJEdk57bpMuCC/kLhKJV4EnMb8205SNrFUQ\r\n"
+
+	want := []string{"password", "secret"}
+
+	all, err := argon2.DecodeAll(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if len(all) != len(want) {
+		t.Fatalf("expected %d hashes, got %d", len(want), len(all))
+	}
+
+	for idx, a := range all {
+		if compareErr := a.Compare(want[idx]); compareErr != nil {
+			t.Errorf("in case %d failed to match", idx)
+		}
+	}
+}
+
+func TestForEachEncoded(t *testing.T) {
+	content := "$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8\n" +
+		"malformed\n" +
+		"\n" +
+		"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$parPWxJrAJEdk57bpMuCC/kLhKJV4EnMb8205SNrFUQ\n"
+
+	var lines []int
+	var failed []int
+
+	err := argon2.ForEachEncoded(strings.NewReader(content), func(lineNum int, _ argon2.Argon2, err error) error {
+		lines = append(lines, lineNum)
+		if err != nil {
+			failed = append(failed, lineNum)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error is not expected: %s", err)
+	}
+
+	if want := []int{1, 2, 4}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("expected lines %v, got %v", want, lines)
+	}
+
+	if want := []int{2}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("expected failed lines %v, got %v", want, failed)
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+
+	err = argon2.ForEachEncoded(strings.NewReader(content), func(int, argon2.Argon2, error) error {
+		calls++
+
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected the callback error, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected the iteration to stop after one call, got %d", calls)
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	salt := argon2.WithSalt([]byte("X9BQMyZQx8SFwwz8"))
+
+	testCases := []string{"password", strings.Repeat("passphrase ", 1024)}
+
+	for idx, testCase := range testCases {
+		a, err := argon2.NewReader(strings.NewReader(testCase), salt)
+		if err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+
+			continue
+		}
+
+		b, err := argon2.New(testCase, salt, argon2.WithMaxLength(len(testCase)))
+		if err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+
+			continue
+		}
+
+		if a.Encode() != b.Encode() {
+			t.Errorf("in case %d expected %s, got %s", idx, b, a)
+		}
+	}
+
+	_, err := argon2.NewReader(strings.NewReader("password"), argon2.WithMaxReadSize(4))
+	if !errors.Is(err, argon2.ErrInputTooLarge) {
+		t.Errorf("expected ErrInputTooLarge, got %v", err)
+	}
+
+	if _, err = argon2.NewReader(failingReader{}); err == nil {
+		t.Errorf("expected the read error to be returned")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"errors"
+	"math"
+	"unicode"
+)
+
+var (
+	// ErrWeakPassword is returned when a password is rejected for being too weak.
+	ErrWeakPassword = errors.New("the password is too weak")
+
+	// ErrEmptyPassword is returned when an empty or whitespace-only password is rejected.
+	ErrEmptyPassword = errors.New("the password is empty")
+
+	// ErrPasswordTooShort is returned when a password is shorter than the configured minimum length.
+	ErrPasswordTooShort = errors.New("the password is too short")
+
+	// ErrPasswordTooLong is returned when a password is longer than the configured maximum length.
+	ErrPasswordTooLong = errors.New("the password is too long")
+)
+
+// Sizes of the character classes used to estimate the entropy of a password.
+const (
+	lowerCharsetSize  = 26
+	upperCharsetSize  = 26
+	digitCharsetSize  = 10
+	symbolCharsetSize = 33
+	otherCharsetSize  = 100
+)
+
+// PasswordEntropyBits returns a rough estimate of the entropy of the given password in bits.
+//
+// This is a heuristic, not a measure of how hard the password is to guess. It returns the lesser of
+// two estimates: the length times the bits of the character classes in use (lowercase, uppercase,
+// digits, symbols and other), and the length times the Shannon entropy of the character frequencies.
+// Dictionary words and common patterns are not detected.
+func PasswordEntropyBits(password string) float64 {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return 0
+	}
+
+	var lower, upper, digit, symbol, other bool
+
+	freq := make(map[rune]int)
+
+	for _, r := range runes {
+		freq[r]++
+
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	var pool int
+
+	for _, class := range []struct {
+		used bool
+		size int
+	}{
+		{lower, lowerCharsetSize},
+		{upper, upperCharsetSize},
+		{digit, digitCharsetSize},
+		{symbol, symbolCharsetSize},
+		{other, otherCharsetSize},
+	} {
+		if class.used {
+			pool += class.size
+		}
+	}
+
+	var shannon float64
+
+	for _, n := range freq {
+		p := float64(n) / float64(len(runes))
+		shannon -= p * math.Log2(p)
+	}
+
+	length := float64(len(runes))
+
+	return math.Min(length*math.Log2(float64(pool)), length*shannon)
+}
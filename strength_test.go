@@ -0,0 +1,121 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestPasswordEntropyBits(t *testing.T) {
+	testCases := []struct {
+		args string
+		min  float64
+		max  float64
+	}{
+		{"", 0, 0},
+		{"aaaa", 0, 0},
+		{"abcd", 8, 8},
+		{"xK9#mQ2$vL7!pR4@", 64, 64},
+	}
+
+	for idx, testCase := range testCases {
+		got := argon2.PasswordEntropyBits(testCase.args)
+		if got < testCase.min || got > testCase.max {
+			t.Errorf("in case %d expected between %f and %f bits, got %f", idx, testCase.min, testCase.max, got)
+		}
+	}
+}
+
+func TestArgon2MinEntropy(t *testing.T) {
+	testCases := []struct {
+		args string
+		want error
+	}{
+		{"aaaa", argon2.ErrWeakPassword},
+		{"password", argon2.ErrWeakPassword},
+		{"xK9#mQ2$vL7!pR4@", nil},
+	}
+
+	for idx, testCase := range testCases {
+		if _, err := argon2.New(testCase.args, argon2.WithMinEntropy(40)); !errors.Is(err, testCase.want) {
+			t.Errorf("in case %d expected %v, got %v", idx, testCase.want, err)
+		}
+	}
+}
+
+func TestArgon2RejectEmpty(t *testing.T) {
+	testCases := []struct {
+		args    string
+		wantErr bool
+	}{
+		{"", true},
+		{" \t\n", true},
+		{" password ", false},
+	}
+
+	for idx, testCase := range testCases {
+		_, err := argon2.New(testCase.args, argon2.WithRejectEmpty(), argon2.WithMemory(1024), argon2.WithIterations(1))
+		if testCase.wantErr && !errors.Is(err, argon2.ErrEmptyPassword) {
+			t.Errorf("in case %d expected ErrEmptyPassword, got %v", idx, err)
+		} else if !testCase.wantErr && err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+		}
+	}
+
+	a := argon2.MustNew("password", argon2.WithMemory(1024), argon2.WithIterations(1))
+	if compareErr := a.Compare(""); !errors.Is(compareErr, argon2.ErrMismatched) {
+		t.Errorf("expected ErrMismatched on an empty password, got %v", compareErr)
+	}
+
+	if _, err := argon2.New(""); err != nil {
+		t.Errorf("expected empty passwords to be hashed by default, got %s", err)
+	}
+}
+
+func TestArgon2PasswordLength(t *testing.T) {
+	testCases := []struct {
+		args    string
+		opts    []argon2.Option
+		wantErr error
+	}{
+		{"password", []argon2.Option{argon2.WithMinLength(8), argon2.WithMaxLength(8)}, nil},
+		{"secret", []argon2.Option{argon2.WithMinLength(8)}, argon2.ErrPasswordTooShort},
+		{"password", []argon2.Option{argon2.WithMaxLength(4)}, argon2.ErrPasswordTooLong},
+		{strings.Repeat("a", 4096), nil, nil},
+		{strings.Repeat("a", 4097), nil, argon2.ErrPasswordTooLong},
+		{strings.Repeat("a", 4097), []argon2.Option{argon2.WithMaxLength(8192)}, nil},
+	}
+
+	for idx, testCase := range testCases {
+		opts := append([]argon2.Option{argon2.WithMemory(1024), argon2.WithIterations(1)}, testCase.opts...)
+
+		_, err := argon2.New(testCase.args, opts...)
+		if testCase.wantErr == nil && err != nil {
+			t.Errorf("in case %d failed to create: %s", idx, err)
+		} else if !errors.Is(err, testCase.wantErr) {
+			t.Errorf("in case %d expected %v, got %v", idx, testCase.wantErr, err)
+		}
+	}
+
+	for idx, opt := range []argon2.Option{argon2.WithMinLength(-1), argon2.WithMaxLength(0)} {
+		if _, err := argon2.New("password", opt); !errors.Is(err, argon2.ErrInvalidOption) {
+			t.Errorf("in case %d expected ErrInvalidOption, got %v", idx, err)
+		}
+	}
+}
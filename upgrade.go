@@ -0,0 +1,109 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+// Upgrade migrates a password from a legacy scheme, e.g. bcrypt, to argon2 on a successful login.
+//
+// The password is checked with the given legacy verification function first. If it passes, the password
+// is hashed with the given options and the new hash is returned along with true, to be stored in place of
+// the legacy one. Otherwise, no hash is computed and it returns false with no error. An error means the
+// legacy check passed but the new hash could not be created.
+func Upgrade(legacyVerify func(password string) bool, password string, opts ...Option) (Argon2, bool, error) {
+	if !legacyVerify(password) {
+		return Argon2{}, false, nil
+	}
+
+	a, err := New(password, opts...)
+	if err != nil {
+		return Argon2{}, true, err
+	}
+
+	return a, true, nil
+}
+
+// IsDeprecatedVariant reports whether the hash uses a variant other than the preferred one, e.g. an argon2i
+// hash once policy requires argon2id. An invalid hash has no variant and is never reported as deprecated.
+func (a Argon2) IsDeprecatedVariant(preferred Variant) bool {
+	return a.isValid && a.variant != preferred
+}
+
+// VerifyAndUpgrade compares the password against the hash and rehashes it with the given parameters on a
+// match, if the hash needs a rehash against them, e.g. it uses a deprecated variant or weaker parameters.
+//
+// The new hash keeps the pepper, key id, normalization and encoder of the current one, so it verifies wherever the
+// current one does. A zero key or salt length in the parameters keeps the one of the current hash.
+//
+// It returns true on a match, along with the new hash to be stored in place of the current one, or the zero
+// value if the current hash is up to date. A mismatch returns false with no error. The parameters are
+// checked before comparing, so invalid ones fail with argon2.ErrInvalidOption whatever the password.
+func (a Argon2) VerifyAndUpgrade(password string, params Params) (Argon2, bool, error) {
+	if params.KeyLength == 0 {
+		params.KeyLength = a.keyLength
+	}
+
+	if params.SaltLength == 0 {
+		params.SaltLength = uint32(len(a.salt))
+	}
+
+	o, err := newOptions(params.options())
+	if err != nil {
+		return Argon2{}, false, err
+	}
+
+	ok, err := a.CompareErr(password)
+	if err != nil || !ok {
+		return Argon2{}, false, err
+	}
+
+	if !a.NeedsRehash(params) {
+		return Argon2{}, true, nil
+	}
+
+	o.pepper = cloneBytes(a.pepper)
+	o.keyID = cloneBytes(a.keyID)
+	o.normalize = a.normalize
+	o.form = a.form
+	o.uniformTiming = a.uniformTiming
+
+	if a.encoder != nil {
+		o.encoder = a.encoder
+	}
+
+	upgraded, err := newBytes([]byte(password), o)
+	if err != nil {
+		return Argon2{}, true, err
+	}
+
+	return upgraded, true, nil
+}
+
+// MigrateVariant compares the candidate against the hash, using its own variant, and on a match returns a
+// new hash of the candidate using the target variant, e.g. to move an argon2i hash to argon2id.
+//
+// The new hash is created with the given options, with the variant overridden by the target one. A mismatch
+// returns false with no error; an error along with true means the new hash could not be created.
+func (a Argon2) MigrateVariant(candidate string, to Variant, opts ...Option) (Argon2, bool, error) {
+	ok, err := a.CompareErr(candidate)
+	if err != nil || !ok {
+		return Argon2{}, false, err
+	}
+
+	migrated, err := New(candidate, append(append([]Option(nil), opts...), WithVariant(to))...)
+	if err != nil {
+		return Argon2{}, true, err
+	}
+
+	return migrated, true, nil
+}
@@ -0,0 +1,224 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestUpgrade(t *testing.T) {
+	calls := 0
+	legacyVerify := func(password string) bool {
+		calls++
+
+		return password == "password"
+	}
+
+	testCases := []struct {
+		args    string
+		opts    []argon2.Option
+		want    bool
+		wantErr error
+	}{
+		{"password", nil, true, nil},
+		{"secret", nil, false, nil},
+		{"password", []argon2.Option{argon2.WithVariant(argon2.VariantI)}, true, nil},
+		{"password", []argon2.Option{argon2.WithMinLength(16)}, true, argon2.ErrPasswordTooShort},
+	}
+
+	for idx, testCase := range testCases {
+		calls = 0
+
+		a, ok, err := argon2.Upgrade(legacyVerify, testCase.args, testCase.opts...)
+		if !errors.Is(err, testCase.wantErr) {
+			t.Errorf("in case %d expected error %v, got %v", idx, testCase.wantErr, err)
+		}
+
+		if ok != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, ok)
+		}
+
+		if calls != 1 {
+			t.Errorf("in case %d expected the legacy verifier to be called once, got %d", idx, calls)
+		}
+
+		if !ok || err != nil {
+			if a.Valid() {
+				t.Errorf("in case %d expected an invalid hash", idx)
+			}
+
+			continue
+		}
+
+		if compareErr := a.Compare(testCase.args); compareErr != nil {
+			t.Errorf("in case %d failed to match the upgraded hash", idx)
+		}
+	}
+}
+
+func TestArgon2IsDeprecatedVariant(t *testing.T) {
+	testCases := []struct {
+		a         argon2.Argon2
+		preferred argon2.Variant
+		want      bool
+	}{
+		{mustNewByEncoded(
+			"$argon2i$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$LemjSGlZG4wIF14JADA5jkdoISphpCdrnpBJdv+BEOM",
+		), argon2.VariantID, true},
+		{mustNewByEncoded(
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+		), argon2.VariantID, false},
+		{argon2.Argon2{}, argon2.VariantID, false},
+	}
+
+	for idx, testCase := range testCases {
+		if got := testCase.a.IsDeprecatedVariant(testCase.preferred); got != testCase.want {
+			t.Errorf("in case %d expected %t, got %t", idx, testCase.want, got)
+		}
+	}
+}
+
+func TestArgon2VerifyAndUpgrade(t *testing.T) {
+	a := mustNewByEncoded(
+		"$argon2i$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$LemjSGlZG4wIF14JADA5jkdoISphpCdrnpBJdv+BEOM",
+	)
+	params := argon2.Params{
+		Variant: argon2.VariantID, Memory: 64, Iterations: 1, Parallelism: 1, KeyLength: 32, SaltLength: 16,
+	}
+
+	upgraded, ok, err := a.VerifyAndUpgrade("secret", params)
+	if err != nil || ok || upgraded.Valid() {
+		t.Errorf("expected a mismatch with no upgrade, got %t, %v", ok, err)
+	}
+
+	upgraded, ok, err = a.VerifyAndUpgrade("password", params)
+	if err != nil || !ok {
+		t.Fatalf("expected a match, got %t, %v", ok, err)
+	}
+
+	if upgraded.Variant() != argon2.VariantID || upgraded.NeedsRehash(params) {
+		t.Errorf("expected an argon2id upgrade, got %s", upgraded.Encode())
+	}
+
+	if compareErr := upgraded.Compare("password"); compareErr != nil {
+		t.Errorf("expected the upgrade to match the password, got %v", compareErr)
+	}
+
+	again, ok, err := upgraded.VerifyAndUpgrade("password", params)
+	if err != nil || !ok || again.Valid() {
+		t.Errorf("expected an up to date hash not to be upgraded, got %t, %v", ok, err)
+	}
+
+	lengths := params
+	lengths.KeyLength, lengths.SaltLength = 0, 0
+
+	again, ok, err = upgraded.VerifyAndUpgrade("password", lengths)
+	if err != nil || !ok || again.Valid() {
+		t.Errorf("expected zero lengths to keep the ones of the hash, got %t, %v", ok, err)
+	}
+
+	invalid := params
+	invalid.KeyLength = 1
+
+	if _, ok, err = a.VerifyAndUpgrade("secret", invalid); ok || !errors.Is(err, argon2.ErrInvalidOption) {
+		t.Errorf("expected error %v before comparing, got %t, %v", argon2.ErrInvalidOption, ok, err)
+	}
+}
+
+func TestArgon2VerifyAndUpgradePepper(t *testing.T) {
+	pepper := []byte("pepper")
+	params := argon2.Params{
+		Variant: argon2.VariantID, Memory: 64, Iterations: 1, Parallelism: 1, KeyLength: 32, SaltLength: 16,
+	}
+
+	a, err := argon2.New(
+		"password",
+		argon2.WithVariant(argon2.VariantI),
+		argon2.WithMemory(64),
+		argon2.WithIterations(1),
+		argon2.WithPepper(pepper),
+	)
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	stored, err := argon2.NewByEncoded(a.Encode(), argon2.WithPepper(pepper))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	upgraded, ok, err := stored.VerifyAndUpgrade("password", params)
+	if err != nil || !ok || !upgraded.Valid() {
+		t.Fatalf("expected an upgrade, got %t, %v", ok, err)
+	}
+
+	withPepper, err := argon2.NewByEncoded(upgraded.Encode(), argon2.WithPepper(pepper))
+	if err != nil {
+		t.Fatalf("failed to decode the upgrade: %s", err)
+	}
+
+	if compareErr := withPepper.Compare("password"); compareErr != nil {
+		t.Errorf("expected the upgrade to match with the pepper, got %v", compareErr)
+	}
+
+	withoutPepper := mustNewByEncoded(upgraded.Encode())
+	if compareErr := withoutPepper.Compare("password"); !errors.Is(compareErr, argon2.ErrMismatched) {
+		t.Errorf("expected the upgrade not to match without the pepper, got %v", compareErr)
+	}
+}
+
+func TestArgon2MigrateVariant(t *testing.T) {
+	a := mustNewByEncoded(
+		"$argon2i$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$LemjSGlZG4wIF14JADA5jkdoISphpCdrnpBJdv+BEOM",
+	)
+	opts := []argon2.Option{argon2.WithMemory(64), argon2.WithIterations(1)}
+
+	migrated, ok, err := a.MigrateVariant("secret", argon2.VariantID, opts...)
+	if err != nil || ok || migrated.Valid() {
+		t.Errorf("expected a mismatch with no migration, got %t, %v", ok, err)
+	}
+
+	migrated, ok, err = a.MigrateVariant("password", argon2.VariantID, opts...)
+	if err != nil || !ok {
+		t.Fatalf("expected a match, got %t, %v", ok, err)
+	}
+
+	if migrated.Variant() != argon2.VariantID || !strings.HasPrefix(migrated.Encode(), "$argon2id$") {
+		t.Errorf("expected an argon2id hash, got %s", migrated.Encode())
+	}
+
+	if compareErr := migrated.Compare("password"); compareErr != nil {
+		t.Errorf("expected the migrated hash to match the password, got %v", compareErr)
+	}
+
+	spare := make([]argon2.Option, 1, 2)
+	spare[0] = argon2.WithMemory(64)
+
+	if _, _, err = a.MigrateVariant("password", argon2.VariantID, spare...); err != nil {
+		t.Fatalf("failed to migrate: %s", err)
+	}
+
+	if spare[:2][1] != nil {
+		t.Errorf("expected the options of the caller not to be written to")
+	}
+
+	if _, _, err = (argon2.Argon2{}).MigrateVariant("password", argon2.VariantID); !errors.Is(err, argon2.ErrInvalid) {
+		t.Errorf("expected error %v, got %v", argon2.ErrInvalid, err)
+	}
+}
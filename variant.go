@@ -0,0 +1,74 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedVariant is returned when an argon2 variant cannot be computed.
+var ErrUnsupportedVariant = errors.New("unsupported variant of argon2")
+
+// Variant is a variant of the argon2 algorithm.
+type Variant int
+
+const (
+	// VariantID is argon2id, the default and recommended variant.
+	VariantID Variant = iota
+
+	// VariantI is argon2i.
+	VariantI
+
+	// VariantD is argon2d.
+	//
+	// golang.org/x/crypto/argon2 does not implement it, so it can be neither created nor verified.
+	VariantD
+)
+
+// String implements fmt.Stringer.
+func (v Variant) String() string {
+	switch v {
+	case VariantID:
+		return "argon2id"
+	case VariantI:
+		return "argon2i"
+	case VariantD:
+		return "argon2d"
+	}
+
+	return fmt.Sprintf("Variant(%d)", int(v))
+}
+
+// parseVariant parses the identifier of a variant, case-insensitively.
+func parseVariant(s string) (Variant, error) {
+	for _, v := range []Variant{VariantID, VariantI, VariantD} {
+		if strings.EqualFold(s, v.String()) {
+			return v, v.supported()
+		}
+	}
+
+	return 0, fmt.Errorf("%w: unknown variant %q", ErrInvalidEncodedHash, s)
+}
+
+// supported returns an error if the variant cannot be computed.
+func (v Variant) supported() error {
+	if v == VariantID || v == VariantI {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnsupportedVariant, v)
+}
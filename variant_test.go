@@ -0,0 +1,138 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestArgon2VariantDecoder(t *testing.T) {
+	testCases := []struct {
+		args string
+		want string
+	}{
+		{
+			"$argon2i$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$LemjSGlZG4wIF14JADA5jkdoISphpCdrnpBJdv+BEOM",
+			"password",
+		},
+		{
+			"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			"password",
+		},
+	}
+
+	for idx, testCase := range testCases {
+		a, err := argon2.NewByEncoded(testCase.args)
+		if err != nil {
+			t.Errorf("in case %d failed to decode: %s", idx, err)
+
+			continue
+		}
+
+		if a.Encode() != testCase.args {
+			t.Errorf("in case %d expected %s, got %s", idx, testCase.args, a)
+		}
+
+		if compareErr := a.Compare(testCase.want); compareErr != nil {
+			t.Errorf("in case %d failed to match", idx)
+		}
+	}
+}
+
+func TestArgon2WithVariant(t *testing.T) {
+	a, err := argon2.New("password", argon2.WithVariant(argon2.VariantI), argon2.WithMemory(8*1024))
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if !strings.HasPrefix(a.Encode(), "$argon2i$") {
+		t.Errorf("expected the argon2i prefix, got %s", a)
+	}
+
+	b, err := argon2.NewByEncoded(a.Encode())
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+
+	if compareErr := b.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+
+	_, err = argon2.New("password", argon2.WithVariant(argon2.VariantD))
+	if !errors.Is(err, argon2.ErrUnsupportedVariant) {
+		t.Errorf("expected ErrUnsupportedVariant, got %v", err)
+	}
+}
+
+func TestArgon2UnsupportedVariants(t *testing.T) {
+	testCases := []struct {
+		args string
+		want error
+	}{
+		{
+			"$argon2d$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			argon2.ErrUnsupportedVariant,
+		},
+		{
+			"$argon2x$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			argon2.ErrInvalidEncodedHash,
+		},
+	}
+
+	for idx, testCase := range testCases {
+		if _, err := argon2.NewByEncoded(testCase.args); !errors.Is(err, testCase.want) {
+			t.Errorf("in case %d expected %v, got %v", idx, testCase.want, err)
+		}
+	}
+}
+
+func TestArgon2VariantAndVersion(t *testing.T) {
+	testCases := []struct {
+		deps        argon2.Argon2
+		wantVariant argon2.Variant
+		wantVersion int
+	}{
+		{
+			mustNewByEncoded(
+				"$argon2i$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$LemjSGlZG4wIF14JADA5jkdoISphpCdrnpBJdv+BEOM",
+			),
+			argon2.VariantI,
+			19,
+		},
+		{
+			mustNewByEncoded(
+				"$argon2id$v=19$m=65536,t=3,p=2$WDlCUU15WlF4OFNGd3d6OA$0nJpNUfEq3ELzeoGwcd+cG4er9wu3DgYCBJb2w3nnI8",
+			),
+			argon2.VariantID,
+			19,
+		},
+		{argon2.MustNew("password", argon2.WithVariant(argon2.VariantI)), argon2.VariantI, 19},
+		{argon2.Argon2{}, argon2.VariantID, 0},
+	}
+
+	for idx, testCase := range testCases {
+		if got := testCase.deps.Variant(); got != testCase.wantVariant {
+			t.Errorf("in case %d expected %s, got %s", idx, testCase.wantVariant, got)
+		}
+
+		if got := testCase.deps.Version(); got != testCase.wantVersion {
+			t.Errorf("in case %d expected version %d, got %d", idx, testCase.wantVersion, got)
+		}
+	}
+}
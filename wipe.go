@@ -0,0 +1,64 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2
+
+import "runtime"
+
+// Wipe zeroes the salt and hashed value and invalidates the hash.
+//
+// Copies of an argon2.Argon2 share the same underlying salt and hashed value, so wiping one wipes them all;
+// use argon2.Argon2.Clone for a copy that survives it.
+func (a *Argon2) Wipe() {
+	wipe(a.salt)
+	wipe(a.hashed)
+
+	a.isValid = false
+}
+
+// Clone returns a copy of the hash that shares no underlying bytes with it.
+func (a Argon2) Clone() Argon2 {
+	c := a
+	c.salt = cloneBytes(a.salt)
+	c.hashed = cloneBytes(a.hashed)
+	c.pepper = cloneBytes(a.pepper)
+	c.fallbackPeppers = nil
+
+	for _, pepper := range a.fallbackPeppers {
+		c.fallbackPeppers = append(c.fallbackPeppers, cloneBytes(pepper))
+	}
+	c.keyID = cloneBytes(a.keyID)
+	c.data = cloneBytes(a.data)
+
+	return c
+}
+
+// cloneBytes returns a copy of the given bytes, keeping nil as nil.
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+
+	return append([]byte{}, b...)
+}
+
+// wipe zeroes the given bytes.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+
+	// Keeps the stores above from being considered dead.
+	runtime.KeepAlive(b)
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 Meraj Sahebdar
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package argon2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/merajsahebdar/argon2"
+)
+
+func TestArgon2WithWipeInput(t *testing.T) {
+	input := []byte("password")
+
+	a, err := argon2.NewBytes(input, argon2.WithWipeInput())
+	if err != nil {
+		t.Fatalf("failed to create: %s", err)
+	}
+
+	if !bytes.Equal(input, make([]byte, len(input))) {
+		t.Errorf("expected the input to be zeroed, got %q", input)
+	}
+
+	if compareErr := a.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+}
+
+func TestArgon2Wipe(t *testing.T) {
+	a := argon2.MustNew("password")
+	a.Wipe()
+
+	if v, err := a.Value(); err != nil || v != nil {
+		t.Errorf("expected a wiped hash to be invalid, got %v, %v", v, err)
+	}
+
+	if compareErr := a.Compare("password"); compareErr == nil {
+		t.Errorf("expected a wiped hash to not match")
+	}
+}
+
+func TestArgon2Clone(t *testing.T) {
+	a := argon2.MustNew("password", argon2.WithPepper([]byte("pepper")))
+	encoded := a.Encode()
+
+	c := a.Clone()
+	a.Wipe()
+
+	if c.Encode() != encoded {
+		t.Errorf("expected %s, got %s", encoded, c)
+	}
+
+	if compareErr := c.Compare("password"); compareErr != nil {
+		t.Errorf("failed to match")
+	}
+
+	if compareErr := a.Compare("password"); compareErr == nil {
+		t.Errorf("expected the wiped original to not match")
+	}
+}